@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/japanese-document/mujidoc/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// printDiagnostics prints diagnostics to the standard error.
+func printDiagnostics(diagnostics []utils.Diagnostic) {
+	for _, d := range diagnostics {
+		fmt.Fprintln(os.Stderr, d)
+	}
+}
+
+// printDiagnosticsJSON prints diagnostics to the standard output as a JSON array.
+func printDiagnosticsJSON(diagnostics []utils.Diagnostic) {
+	content, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		log.Fatalf("%+v", errors.WithStack(err))
+	}
+	fmt.Println(string(content))
+}
+
+// getEnvDuration returns the environment variable as a time.Duration.
+// If the environment variable is empty, it returns defaultValue.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("%+v", errors.Wrapf(err, "%s is not a duration", key))
+	}
+	return d
+}
+
+// createExternalLinkChecker creates an ExternalLinkChecker from the environment variables.
+func createExternalLinkChecker() *utils.ExternalLinkChecker {
+	client := &http.Client{Timeout: getEnvDuration("EXTERNAL_LINK_TIMEOUT", utils.DEFAULT_EXTERNAL_LINK_TIMEOUT)}
+	checker := utils.NewExternalLinkChecker(client)
+	checker.Concurrency = getEnvInt("EXTERNAL_LINK_CONCURRENCY", utils.DEFAULT_EXTERNAL_LINK_CONCURRENCY)
+	checker.HostInterval = getEnvDuration("EXTERNAL_LINK_HOST_INTERVAL", utils.DEFAULT_EXTERNAL_LINK_HOST_INTERVAL)
+	checker.CacheTTL = getEnvDuration("EXTERNAL_LINK_CACHE_TTL", utils.DEFAULT_EXTERNAL_LINK_CACHE_TTL)
+	if cacheFile, exists := os.LookupEnv("EXTERNAL_LINK_CACHE"); exists {
+		checker.CacheFile = cacheFile
+	}
+	ignore, err := utils.ParsePatterns(os.Getenv("EXTERNAL_LINK_IGNORE"))
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	checker.Ignore = ignore
+	allow, err := utils.ParsePatterns(os.Getenv("EXTERNAL_LINK_ALLOW"))
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	checker.Allow = allow
+	return checker
+}
+
+// checkLinks checks the links of the generated HTML files and returns the problems.
+// If external is true, the external links are also checked.
+func checkLinks(outputDir, baseURL string, internal, external bool) []utils.Diagnostic {
+	diagnostics := []utils.Diagnostic{}
+	if internal {
+		ds, err := utils.CheckLinks(outputDir, baseURL)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		diagnostics = append(diagnostics, ds...)
+	}
+	if external {
+		ds, err := utils.CheckExternalLinks(context.Background(), createExternalLinkChecker(), outputDir, baseURL)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		diagnostics = append(diagnostics, ds...)
+	}
+	return diagnostics
+}
+
+// createLintOptions creates the options of the linter from the environment variables.
+func createLintOptions() utils.LintOptions {
+	disabled, err := utils.ParseLintRules(os.Getenv("LINT_DISABLE"))
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	spacing := os.Getenv("LINT_SPACING")
+	if spacing != "" && spacing != utils.LINT_SPACING_ALWAYS && spacing != utils.LINT_SPACING_NEVER {
+		log.Fatalf("%+v", errors.Errorf("LINT_SPACING must be %s or %s: %s", utils.LINT_SPACING_ALWAYS, utils.LINT_SPACING_NEVER, spacing))
+	}
+	return utils.LintOptions{
+		Disabled:          disabled,
+		MaxSentenceLength: getEnvInt("LINT_MAX_SENTENCE_LENGTH", utils.DEFAULT_LINT_MAX_SENTENCE_LENGTH),
+		Spacing:           spacing,
+	}
+}
+
+// lintSource checks the Japanese text of the markdown files in sourceDir and returns the problems.
+func lintSource(sourceDir string) []utils.Diagnostic {
+	markDownFileNames, err := utils.GetMarkDownFileNames(utils.FilePath{}, sourceDir)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	utils.ConfigureMarkdown(createMarkdownOptions())
+	diagnostics, err := utils.LintFiles(markDownFileNames, createLintOptions())
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	return diagnostics
+}
+
+// check runs "mujidoc check" and returns the exit code.
+// The links are verified in the HTML files in OUTPUT_DIR, so the site must be built beforehand.
+// The text is verified in the markdown files in SOURCE_DIR.
+func check(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	links := flags.Bool("links", false, "check internal links, fragments and assets of the generated HTML files")
+	external := flags.Bool("external", false, "check external links of the generated HTML files")
+	lint := flags.Bool("lint", false, "check the Japanese text of the markdown files")
+	format := flags.String("format", "text", "output format: text or json")
+	err := flags.Parse(args)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if !*links && !*external && !*lint || *format != "text" && *format != "json" {
+		flags.Usage()
+		return 2
+	}
+
+	diagnostics := []utils.Diagnostic{}
+	if *lint {
+		diagnostics = append(diagnostics, lintSource(os.Getenv("SOURCE_DIR"))...)
+	}
+	if *links || *external {
+		outputDir := os.Getenv("OUTPUT_DIR")
+		baseURL := strings.Trim(os.Getenv("BASE_URL"), "/")
+		diagnostics = append(diagnostics, checkLinks(outputDir, baseURL, *links, *external)...)
+	}
+	if *format == "json" {
+		printDiagnosticsJSON(diagnostics)
+	} else {
+		printDiagnostics(diagnostics)
+	}
+	if len(diagnostics) > 0 {
+		return 1
+	}
+	return 0
+}
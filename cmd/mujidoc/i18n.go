@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/japanese-document/mujidoc/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// printTranslationStatuses prints the statuses of the translations to the standard output as a table.
+func printTranslationStatuses(statuses []utils.TranslationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tLANGUAGE\tSTATUS\tFILE\tSOURCE HASH")
+	for _, s := range statuses {
+		file := s.File
+		if file == "" {
+			file = "-"
+		}
+		hash := s.SourceHash
+		if hash == "" {
+			hash = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Key, s.Language, s.Status, file, hash)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("%+v", errors.WithStack(err))
+	}
+}
+
+// i18nStatus runs "mujidoc i18n status" and returns the exit code.
+// It reads the markdown files in SOURCE_DIR and reports the missing and stale translations.
+func i18nStatus(args []string) int {
+	flags := flag.NewFlagSet("i18n status", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	err := flags.Parse(args)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if *format != "text" && *format != "json" {
+		flags.Usage()
+		return 2
+	}
+
+	sourceDir := os.Getenv("SOURCE_DIR")
+	markDownFileNames, err := utils.GetMarkDownFileNames(utils.FilePath{}, sourceDir)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	utils.ConfigureMarkdown(createMarkdownOptions())
+	docs, err := utils.ParseDocuments(markDownFileNames)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	statuses, err := utils.TranslationStatuses(docs, sourceDir)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if *format == "json" {
+		content, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			log.Fatalf("%+v", errors.WithStack(err))
+		}
+		fmt.Println(string(content))
+	} else {
+		printTranslationStatuses(statuses)
+	}
+	return 0
+}
+
+// i18n runs the subcommands of "mujidoc i18n" and returns the exit code.
+func i18n(args []string) int {
+	if len(args) == 0 || args[0] != "status" {
+		fmt.Fprintln(os.Stderr, "usage: mujidoc i18n status [--format text|json]")
+		return 2
+	}
+	return i18nStatus(args[1:])
+}
@@ -6,9 +6,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/japanese-document/mujidoc/internal/css"
+	"github.com/japanese-document/mujidoc/internal/js"
 	"github.com/japanese-document/mujidoc/internal/utils"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
@@ -22,36 +26,70 @@ func init() {
 	}
 }
 
-func createPageHtmlFileTask(markDownFileName, indexMenu, pageLayout, sourceDir, outputDir, baseUrl string) func() error {
-	return func() error {
-		content, err := os.ReadFile(markDownFileName)
+// site is a source tree that is generated into a directory.
+// If versions are configured, each version is a site.
+type site struct {
+	sourceDir string
+	// workDir is the directory of the markdown files in the working tree.
+	// It is different from sourceDir if the version is read from a git ref.
+	workDir   string
+	outputDir string
+	// baseURL is the URL of the pages. It ends with the name of the version if versions are configured.
+	baseURL string
+	// assetURL is the URL of the CSS and JavaScript files that are shared by the versions.
+	assetURL     string
+	version      string
+	location     *time.Location // location is the time zone of the dates in the layouts.
+	docs         []*utils.Document
+	translations *utils.Translations
+	versions     *utils.Versions
+	sourceLinks  utils.SourceLinks
+	// gitRoot is the root of the git repository the source links point to, or "" outside a repository.
+	gitRoot string
+}
+
+// layout replaces the placeholders of the language, the version, the history and the source links in a layout for the page at path.
+// doc is nil for the index pages.
+func (s *site) layout(layout string, doc *utils.Document, key, lang, path string) (string, error) {
+	layout = s.translations.LocalizeLayout(layout, key, lang)
+	layout = utils.HistoryLayout(layout, doc, s.location)
+	sourcePath := ""
+	if doc != nil {
+		// markdownファイルのリポジトリのルートからのパスを求める
+		rel, err := filepath.Rel(s.sourceDir, doc.FileName)
 		if err != nil {
-			return errors.WithStack(err)
+			return "", errors.WithStack(err)
 		}
-		md, err := utils.GetMd(string(content))
-		if err != nil {
-			return err
+		sourcePath = utils.RepositoryPath(s.gitRoot, filepath.Join(s.workDir, rel))
+	}
+	layout = s.sourceLinks.Layout(layout, sourcePath)
+	return s.versions.VersionLayout(layout, s.version, path, getEnv("VERSION_BANNER", utils.DEFAULT_VERSION_BANNER)), nil
+}
+
+func createPageHtmlFileTask(doc *utils.Document, indexMenu *utils.IndexMenu, s *site, pageLayout, diagramScript string, tocOpts utils.TOCOptions) func() error {
+	return func() error {
+		url := doc.URL(s.sourceDir, s.baseURL)
+		cssPath := fmt.Sprintf("%s/app.css?v=%s", s.assetURL, css.Version())
+		scripts := createScripts(s.assetURL)
+		if doc.HasDiagrams {
+			scripts = diagramScript + scripts
 		}
-		title := utils.CreateTitle(md)
-		dir, name := utils.GetDirAndName(markDownFileName)
-		url := utils.CreateURL(dir, name, sourceDir, baseUrl)
-		headerList, err := utils.CreateHeaderList(md)
+		layout, err := s.layout(pageLayout, doc, doc.TranslationKey(s.sourceDir), doc.Language(s.sourceDir), doc.URL(s.sourceDir, ""))
 		if err != nil {
 			return err
 		}
-		cssPath := fmt.Sprintf("%s/app.css?v=%s", baseUrl, css.Version())
-		page, err := utils.CreatePage(pageLayout, md, title, url, cssPath, indexMenu, headerList)
+		page, err := utils.CreatePage(layout, doc, url, cssPath, scripts, indexMenu.Render(url), tocOpts)
 		if err != nil {
 			return err
 		}
-		dirPath := utils.CreateHTMLFileDir(dir, sourceDir, outputDir)
+		htmlFileName := doc.HTMLFileName(s.sourceDir, s.outputDir)
+		dirPath := filepath.Dir(htmlFileName)
 		if !utils.IsDirExists(dirPath) {
 			err := os.MkdirAll(dirPath, os.ModePerm)
 			if err != nil {
 				return errors.WithStack(err)
 			}
 		}
-		htmlFileName := filepath.Join(dirPath, fmt.Sprintf("%s.html", name))
 		err = os.WriteFile(htmlFileName, []byte(page), 0644)
 		if err != nil {
 			return errors.WithStack(err)
@@ -70,101 +108,452 @@ func createCopyImageDirTask(sourceDir, outputDir string) func() error {
 	}
 }
 
-func createIndexHtmlFileTask(layout, outputDir, baseURL, header, title, description string, indexItems []utils.IndexItem) func() error {
+// createIndexHtmlFileTask returns a task that generates index.html of a language.
+// The index page of a language other than the default language is generated in the directory of the language.
+func createIndexHtmlFileTask(layout string, s *site, lang, header, title, description string, indexItems []utils.IndexItem, descriptions bool) func() error {
 	return func() error {
 		indexPageLayout, err := os.ReadFile(layout)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		cssPath := fmt.Sprintf("%s/app.css?v=%s", baseURL, css.Version())
-		indexPage, err := utils.CreateIndexPage(
-			string(indexPageLayout), baseURL, header, title, description, cssPath, indexItems)
+		cssPath := fmt.Sprintf("%s/app.css?v=%s", s.assetURL, css.Version())
+		scripts := createScripts(s.assetURL)
+		path := utils.LanguagePrefix(lang) + "/"
+		localizedLayout, err := s.layout(string(indexPageLayout), nil, utils.INDEX_TRANSLATION, lang, path)
+		if err != nil {
+			return err
+		}
+		indexPage, err := utils.CreateIndexPage(localizedLayout, s.baseURL+utils.LanguagePrefix(lang), header, title, description, cssPath, scripts, indexItems, descriptions)
 		if err != nil {
 			return err
 		}
-		htmlFileName := filepath.Join(outputDir, "index.html")
+		htmlFileName := filepath.Join(s.outputDir, utils.LanguagePrefix(lang), "index.html")
 		return os.WriteFile(htmlFileName, []byte(indexPage), 0644)
 	}
 }
 
+// createScripts returns the script elements that are embedded in every page.
+func createScripts(baseURL string) string {
+	return fmt.Sprintf(`<script src="%s/%s?v=%s" defer></script>`, baseURL, utils.JS_FILE_NAME, js.Version())
+}
+
+// createDiagramScript returns the script element of the diagram renderer and the task that copies it to outputDir.
+// The script is linked only from the pages that have diagrams. If scriptPath is empty, it returns no script.
+func createDiagramScript(scriptPath, outputDir, baseURL string) (string, func() error) {
+	if scriptPath == "" {
+		return "", func() error { return nil }
+	}
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		fatalf("%+v", errors.WithStack(err))
+	}
+	name := filepath.Base(scriptPath)
+	script := fmt.Sprintf(`<script src="%s/%s?v=%s" defer></script>`, baseURL, name, uuid.NewSHA1(uuid.Nil, content))
+	return script, func() error {
+		return errors.WithStack(os.WriteFile(filepath.Join(outputDir, name), content, 0644))
+	}
+}
+
+// getLanguageEnv returns the environment variable for a language, such as CATEGORIES_EN for "en".
+// If the environment variable for the language is empty, it returns the environment variable for all languages.
+func getLanguageEnv(key, lang string) string {
+	if lang != "" {
+		suffix := strings.ToUpper(strings.ReplaceAll(lang, "-", "_"))
+		if value := os.Getenv(key + "_" + suffix); value != "" {
+			return value
+		}
+	}
+	return os.Getenv(key)
+}
+
+// getEnv returns the environment variable.
+// If the environment variable is empty, it returns defaultValue.
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt returns the environment variable as an integer.
+// If the environment variable is empty, it returns defaultValue.
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		fatalf("%+v", errors.Wrapf(err, "%s is not a number", key))
+	}
+	return i
+}
+
+// createTOCOptions creates the options of the table of contents from the environment variables.
+// It exits if the levels are invalid.
+func createTOCOptions() utils.TOCOptions {
+	opts := utils.TOCOptions{
+		MinLevel:  getEnvInt("TOC_MIN_LEVEL", utils.DEFAULT_TOC_MIN),
+		MaxLevel:  getEnvInt("TOC_MAX_LEVEL", utils.DEFAULT_TOC_MAX),
+		List:      os.Getenv("TOC_LIST"),
+		ScrollSpy: os.Getenv("TOC_SCROLL_SPY") == "true",
+	}
+	if err := opts.Validate(); err != nil {
+		fatalf("%+v", errors.WithMessage(err, ".env.mujidoc"))
+	}
+	return opts
+}
+
+// createMarkdownOptions creates the options of markdown from the environment variables.
+func createMarkdownOptions() utils.MarkdownOptions {
+	languages, err := utils.ParseHighlightLanguages(os.Getenv("HIGHLIGHT_LANGUAGES"))
+	if err != nil {
+		fatalf("%+v", err)
+	}
+	shortcodeDir := os.Getenv("SHORTCODE_DIR")
+	if shortcodeDir == "" {
+		shortcodeDir = utils.DEFAULT_SHORTCODE_DIR
+	}
+	shortcodes, err := utils.LoadShortcodes(shortcodeDir, os.Getenv("SHORTCODE_TRUSTED") == "true")
+	if err != nil {
+		fatalf("%+v", err)
+	}
+	style := os.Getenv("HIGHLIGHT_STYLE")
+	if style == "" {
+		style = utils.DEFAULT_HIGHLIGHT_STYLE
+	}
+	diagramCommands := map[string][]string{}
+	for kind, key := range map[string]string{"mermaid": "DIAGRAM_COMMAND_MERMAID", "plantuml": "DIAGRAM_COMMAND_PLANTUML"} {
+		if command := strings.Fields(os.Getenv(key)); len(command) > 0 {
+			diagramCommands[kind] = command
+		}
+	}
+	linkifyProtocols := []string{}
+	for _, protocol := range strings.Split(os.Getenv("MARKDOWN_LINKIFY_PROTOCOLS"), ",") {
+		if protocol = strings.TrimSpace(protocol); protocol != "" {
+			linkifyProtocols = append(linkifyProtocols, protocol)
+		}
+	}
+	return utils.MarkdownOptions{
+		HeadingID: os.Getenv("HEADING_ID"),
+		Highlight: utils.HighlightOptions{
+			Enabled:   os.Getenv("HIGHLIGHT") != "false",
+			Style:     style,
+			Languages: languages,
+		},
+		CopyButton:        os.Getenv("CODE_COPY_BUTTON") == "true",
+		Shortcodes:        shortcodes,
+		Footnote:          os.Getenv("MARKDOWN_FOOTNOTE") == "true",
+		DefinitionList:    os.Getenv("MARKDOWN_DEFINITION_LIST") == "true",
+		Typographer:       os.Getenv("MARKDOWN_TYPOGRAPHER") == "true",
+		CJK:               os.Getenv("MARKDOWN_CJK") == "true",
+		DisableLinkify:    os.Getenv("MARKDOWN_LINKIFY") == "false",
+		LinkifyProtocols:  linkifyProtocols,
+		HardWraps:         os.Getenv("MARKDOWN_HARD_WRAPS") == "true",
+		Unsafe:            os.Getenv("MARKDOWN_UNSAFE") == "true",
+		Math:              os.Getenv("MARKDOWN_MATH") == "true",
+		DiagramCommands:   diagramCommands,
+		DiagramScript:     os.Getenv("DIAGRAM_SCRIPT"),
+		Ruby:              os.Getenv("MARKDOWN_RUBY") == "true",
+		WritingMode:       os.Getenv("WRITING_MODE"),
+		DescriptionLength: getEnvInt("DESCRIPTION_LENGTH", utils.DEFAULT_DESCRIPTION_LENGTH),
+		Languages:         utils.ParseLanguages(os.Getenv("LANGUAGES")),
+	}
+}
+
+// cleanups holds the functions that remove the temporary checkouts of the versions.
+// log.Fatalf and os.Exit do not run deferred functions, so fatalf and exit run them instead.
+var cleanups []func()
+
+// runCleanups runs the cleanup functions in the reverse order of their registration.
+func runCleanups() {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	cleanups = nil
+}
+
+// fatalf runs the cleanup functions and calls log.Fatalf.
+func fatalf(format string, v ...any) {
+	runCleanups()
+	log.Fatalf(format, v...)
+}
+
+// exit runs the cleanup functions and exits with code.
+func exit(code int) {
+	runCleanups()
+	os.Exit(code)
+}
+
 func cleanup(outputDir string) {
 	err := os.RemoveAll(outputDir)
 	if err != nil {
-		log.Fatalf("%+v", err)
+		fatalf("%+v", err)
 	}
 	err = os.Mkdir(outputDir, os.ModePerm)
 	if err != nil {
-		log.Fatalf("%+v", err)
+		fatalf("%+v", err)
 	}
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(check(os.Args[2:]))
+		case "i18n":
+			os.Exit(i18n(os.Args[2:]))
+		default:
+			fatalf("unknown command: %s", os.Args[1])
+		}
+	}
+	build()
+}
+
+// build generates the site from the markdown files.
+// If VERSIONS is set, each version is generated into the directory of its name.
+func build() {
 	eg, _ := errgroup.WithContext(context.Background())
 
 	outputDir := os.Getenv("OUTPUT_DIR")
 	cleanup(outputDir)
 
 	sourceDir := os.Getenv("SOURCE_DIR")
-	fs := utils.FilePath{}
-	markDownFileNames, err := utils.GetMarkDownFileNames(fs, sourceDir)
-	if err != nil {
-		log.Fatalf("%+v", err)
-	}
 	baseURL := strings.Trim(os.Getenv("BASE_URL"), "/")
 
-	// 各ページのデータを取得
-	pages := []*utils.Page{}
-	if os.Getenv("SINGLE_PAGE") != "true" {
-		pages, err = utils.CreatePages(markDownFileNames, sourceDir, baseURL, os.Getenv("CATEGORIES"))
+	markdownOptions := createMarkdownOptions()
+	utils.ConfigureMarkdown(markdownOptions)
+	tocOpts := createTOCOptions()
+	if markdownOptions.Highlight.Enabled {
+		highlightCSS, err := utils.CreateHighlightCSS(markdownOptions.Highlight.Style)
 		if err != nil {
-			log.Fatalf("%+v", err)
+			fatalf("%+v", err)
 		}
+		css.Append(highlightCSS)
 	}
 
-	if os.Getenv("RSS") == "true" {
-		task := utils.CreateRssFileTask(pages, os.Getenv("TIME_ZONE"), outputDir, baseURL, os.Getenv("INDEX_PAGE_TITLE"),
-			os.Getenv("INDEX_PAGE_DESCRIPTION"))
-		eg.Go(task)
+	// バージョンごとのソースを用意する
+	versionList, err := utils.ParseVersions(os.Getenv("VERSIONS"))
+	if err != nil {
+		fatalf("%+v", err)
 	}
-
-	// もくじページに表示するページ一覧のデータを取得
-	indexItems, err := utils.CreateIndexItems(pages)
+	versions, err := utils.NewVersions(versionList, os.Getenv("LATEST_VERSION"), baseURL)
+	if err != nil {
+		fatalf("%+v", errors.WithMessage(err, ".env.mujidoc"))
+	}
+	location, err := time.LoadLocation(os.Getenv("TIME_ZONE"))
 	if err != nil {
-		log.Fatalf("%+v", err)
+		fatalf("%+v", errors.WithStack(err))
+	}
+	sourceLinks := utils.SourceLinks{Edit: os.Getenv("EDIT_URL"), Source: os.Getenv("SOURCE_URL"), History: os.Getenv("HISTORY_URL")}
+	gitRoot := ""
+	if sourceLinks.Edit != "" || sourceLinks.Source != "" || sourceLinks.History != "" {
+		// gitのリポジトリの外ではmarkdownファイルのパスをそのまま使う
+		if root, err := utils.GitRoot(); err == nil {
+			gitRoot = root
+		}
+	}
+	sites := []*site{{sourceDir: sourceDir, workDir: sourceDir, outputDir: outputDir, baseURL: baseURL, assetURL: baseURL,
+		location: location, versions: versions, sourceLinks: sourceLinks, gitRoot: gitRoot}}
+	if len(versionList) > 0 {
+		sites = []*site{}
+		for _, version := range versionList {
+			dir, remove, err := version.Checkout(sourceDir)
+			if err != nil {
+				fatalf("%+v", err)
+			}
+			cleanups = append(cleanups, remove)
+			workDir := dir
+			links := sourceLinks
+			if strings.HasPrefix(version.Source, utils.GIT_VERSION_PREFIX) {
+				workDir = sourceDir
+				links.Ref = strings.TrimPrefix(version.Source, utils.GIT_VERSION_PREFIX)
+			}
+			sites = append(sites, &site{
+				sourceDir:   dir,
+				workDir:     workDir,
+				outputDir:   filepath.Join(outputDir, version.Name),
+				baseURL:     baseURL + "/" + version.Name,
+				assetURL:    baseURL,
+				version:     version.Name,
+				location:    location,
+				versions:    versions,
+				sourceLinks: links,
+				gitRoot:     gitRoot,
+			})
+		}
+	}
+
+	// gitの履歴から作成日、更新日と著者を取得する
+	var histories *utils.GitHistories
+	if os.Getenv("GIT_HISTORY") == "true" {
+		cacheFile := utils.DEFAULT_GIT_HISTORY_CACHE_FILE
+		if value, exists := os.LookupEnv("GIT_HISTORY_CACHE"); exists {
+			cacheFile = value
+		}
+		histories, err = utils.LoadGitHistories(cacheFile)
+		if err != nil {
+			fatalf("%+v", err)
+		}
 	}
 
-	// ページの左側に表示するもくじのHTMLを生成
-	indexMenu := utils.CreateIndexMenu(indexItems)
+	// markdownファイルを読み込んでASTに変換する
+	for _, s := range sites {
+		markDownFileNames, err := utils.GetMarkDownFileNames(utils.FilePath{}, s.sourceDir)
+		if err != nil {
+			fatalf("%+v", err)
+		}
+		s.docs, err = utils.LoadDocuments(markDownFileNames, s.sourceDir)
+		if err != nil {
+			fatalf("%+v", err)
+		}
+		// gitのrefから読み込んだバージョンのファイルは一時ディレクトリにあるので履歴を持たない
+		if histories != nil && s.sourceDir == s.workDir {
+			histories.Apply(s.docs)
+		}
+		for _, doc := range s.docs {
+			versions.Register(s.version, doc.URL(s.sourceDir, ""))
+		}
+		for _, l := range markdownOptions.Languages {
+			versions.Register(s.version, utils.LanguagePrefix(l.Code)+"/")
+		}
+
+		// markdownファイルへのリンクを生成するページのURLに変換する
+		err = utils.ResolveLinks(s.docs, s.sourceDir, s.baseURL, os.Getenv("LINK_STRICT") == "true")
+		if err != nil {
+			fatalf("%+v", err)
+		}
+		for _, doc := range s.docs {
+			printDiagnostics(doc.Diagnostics)
+		}
+		s.translations = utils.NewTranslations(s.docs, s.sourceDir, s.baseURL)
+	}
 
 	// ページレイアウトを取得
 	_pageLayout, err := os.ReadFile(os.Getenv("PAGE_LAYOUT"))
 	if err != nil {
-		log.Fatalf("%+v", errors.WithStack(err))
+		fatalf("%+v", errors.WithStack(err))
 	}
 	pageLayout := string(_pageLayout)
 
-	// markdownからhtmlを生成する
-	for _, markDownFileName := range markDownFileNames {
-		task := createPageHtmlFileTask(markDownFileName, indexMenu, pageLayout, sourceDir, outputDir, baseURL)
-		eg.Go(task)
+	diagramScript, task := createDiagramScript(os.Getenv("DIAGRAM_SCRIPT"), outputDir, baseURL)
+	eg.Go(task)
+
+	for _, s := range sites {
+		buildSite(eg, s, markdownOptions.Languages, pageLayout, diagramScript, tocOpts)
 	}
 
-	// 画像をコピーする
-	task := createCopyImageDirTask(sourceDir, outputDir)
-	eg.Go(task)
+	// ページごとの入力ファイルの一覧を作成する
+	if dependencyFile := os.Getenv("DEPENDENCY_FILE"); dependencyFile != "" {
+		rules := ""
+		for _, s := range sites {
+			// gitのrefから読み込んだバージョンは一時ディレクトリのファイルから生成されるので含めない
+			if s.sourceDir == s.workDir {
+				rules += utils.CreateDependencyRules(s.docs, s.sourceDir, s.outputDir)
+			}
+		}
+		eg.Go(utils.CreateDependencyFileTask(rules, dependencyFile))
+	}
 
-	// index.htmlを作成する
-	if os.Getenv("SINGLE_PAGE") != "true" {
-		task = createIndexHtmlFileTask(os.Getenv("INDEX_PAGE_LAYOUT"), outputDir, baseURL, os.Getenv("INDEX_PAGE_HEADER"),
-			os.Getenv("INDEX_PAGE_TITLE"), os.Getenv("INDEX_PAGE_DESCRIPTION"), indexItems)
-		eg.Go(task)
+	// 最新のバージョンへ移動するindex.htmlを作成する
+	if len(versionList) > 0 {
+		eg.Go(func() error {
+			return versions.WriteVersionRedirect(outputDir)
+		})
 	}
 
 	// CSSファイルを作成する
 	task = css.CreateWriteTask(outputDir, utils.CSS_FILE_NAME)
 	eg.Go(task)
 
+	// JavaScriptファイルを作成する
+	task = js.CreateWriteTask(outputDir, utils.JS_FILE_NAME)
+	eg.Go(task)
+
 	if err := eg.Wait(); err != nil {
-		log.Fatalf("%+v", err)
+		fatalf("%+v", err)
+	}
+	// 一時ディレクトリのファイルは生成が終わったら不要になる
+	runCleanups()
+
+	// 生成したHTMLのリンクを検査する
+	checkInternal := os.Getenv("CHECK_LINKS") == "true"
+	checkExternal := os.Getenv("CHECK_EXTERNAL_LINKS") == "true"
+	if checkInternal || checkExternal {
+		diagnostics := checkLinks(outputDir, baseURL, checkInternal, checkExternal)
+		printDiagnostics(diagnostics)
+		if len(diagnostics) > 0 {
+			exit(1)
+		}
+	}
+}
+
+// buildSite adds the tasks that generate the pages, the index pages, the RSS feeds and the images of a site.
+// The pages are generated for each language.
+func buildSite(eg *errgroup.Group, s *site, languages []utils.Language, pageLayout, diagramScript string, tocOpts utils.TOCOptions) {
+	// 言語ごとにページを生成する
+	docsByLanguage := utils.GroupDocumentsByLanguage(s.docs, s.sourceDir)
+	langs := []string{""}
+	if len(languages) > 0 {
+		langs = []string{}
+		for _, l := range languages {
+			langs = append(langs, l.Code)
+		}
+	}
+	for _, lang := range langs {
+		langDocs := docsByLanguage[lang]
+		langOutputDir := filepath.Join(s.outputDir, utils.LanguagePrefix(lang))
+		if err := os.MkdirAll(langOutputDir, os.ModePerm); err != nil {
+			fatalf("%+v", errors.WithStack(err))
+		}
+
+		// 各ページのデータを取得
+		pages := []*utils.Page{}
+		if os.Getenv("SINGLE_PAGE") != "true" {
+			var err error
+			pages, err = utils.CreatePages(langDocs, s.sourceDir, s.baseURL, getLanguageEnv("CATEGORIES", lang))
+			if err != nil {
+				fatalf("%+v", err)
+			}
+		}
+
+		if os.Getenv("RSS") == "true" {
+			task := utils.CreateRssFileTask(pages, os.Getenv("TIME_ZONE"), langOutputDir, s.baseURL+utils.LanguagePrefix(lang),
+				getLanguageEnv("INDEX_PAGE_TITLE", lang), getLanguageEnv("INDEX_PAGE_DESCRIPTION", lang))
+			eg.Go(task)
+		}
+
+		// もくじページに表示するページ一覧のデータを取得
+		indexItems, err := utils.CreateIndexItems(pages)
+		if err != nil {
+			fatalf("%+v", err)
+		}
+
+		// ページの左側に表示するもくじを生成
+		indexMenu := utils.NewIndexMenu(indexItems, os.Getenv("INDEX_MENU_OPEN") == "all")
+
+		// markdownからhtmlを生成する
+		for _, doc := range langDocs {
+			task := createPageHtmlFileTask(doc, indexMenu, s, pageLayout, diagramScript, tocOpts)
+			eg.Go(task)
+		}
+
+		// index.htmlを作成する
+		if os.Getenv("SINGLE_PAGE") != "true" {
+			task := createIndexHtmlFileTask(os.Getenv("INDEX_PAGE_LAYOUT"), s, lang,
+				getLanguageEnv("INDEX_PAGE_HEADER", lang), getLanguageEnv("INDEX_PAGE_TITLE", lang), getLanguageEnv("INDEX_PAGE_DESCRIPTION", lang),
+				indexItems, os.Getenv("INDEX_PAGE_DESCRIPTIONS") == "true")
+			eg.Go(task)
+		}
+	}
+
+	// sitemap.xmlを作成する
+	if os.Getenv("SITEMAP") == "true" {
+		urls := utils.CreateSitemapURLs(s.docs, s.sourceDir, s.baseURL, os.Getenv("SINGLE_PAGE") != "true")
+		eg.Go(utils.CreateSitemapFileTask(urls, s.outputDir))
 	}
+
+	// 画像をコピーする
+	task := createCopyImageDirTask(s.sourceDir, s.outputDir)
+	eg.Go(task)
 }
@@ -3,6 +3,7 @@ module github.com/japanese-document/mujidoc
 go 1.22.0
 
 require (
+	github.com/alecthomas/chroma/v2 v2.14.0
 	github.com/joho/godotenv v1.5.1
 	github.com/microcosm-cc/bluemonday v1.0.26
 	github.com/pkg/errors v0.9.1
@@ -10,6 +11,8 @@ require (
 	golang.org/x/sync v0.6.0
 )
 
+require github.com/dlclark/regexp2 v1.11.0 // indirect
+
 require (
 	github.com/aymerick/douceur v0.2.0 // indirect
 	github.com/google/uuid v1.6.0
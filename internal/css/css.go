@@ -7,5 +7,5 @@ const CSS_CONTENT = `
 The MIT License (MIT)
 Copyright (c) 2021 GitHub Inc.
 */
-@media (width <= 1200px){.header-list,.index-menu{display:none}.left-side,.right-side{border:none}}*,:after,:before{box-sizing:border-box}body,html{height:100%}body{font-family:Meiryo,Hiragino Fixed,sans-serif;margin:0}body::-webkit-scrollbar-thumb{background-clip:content-box;background-color:grey;border:4px solid transparent;border-radius:8px;height:64px}body::-webkit-scrollbar{width:16px}.container{display:grid;grid-template-columns:1fr 1000px 1fr;grid-template-rows:1fr auto}.left-side{grid-column:1/2;grid-row:1/3;height:max-content;min-height:100%}.left-side:has(.index-menu){border-right:1px solid var(--color-border-muted)}.right-side{grid-column:3/4;grid-row:1/3}.right-side:has(.header-list){border-left:1px solid var(--color-border-muted)}.main{grid-row:1/2}.main,footer{grid-column:2/3;padding:1.25rem}footer{display:block;font-size:16px;grid-row:2/3;text-align:center}a{text-decoration:none}table{border-collapse:collapse}.index-menu p{font-size:14px;margin-bottom:0;margin-left:17px;margin-top:4px}.index-menu p:last-child{margin-bottom:4px}.index-menu{margin-left:auto;padding:1.25rem;position:sticky;top:0;width:max-content}:is(.index-menu,.header-list) a{color:#000}.header-list{padding:1.25rem;position:sticky;top:0}.header-list p{font-size:14px;margin-bottom:4px;margin-top:0}.header-list .h2{margin-left:14px}.header-list .h3{margin-left:28px}.header-list .h4{margin-left:42px}.markdown-body img{display:block;margin:auto}.markdown-body :is(h1,h2,h3,h4,h5,h6) a{-webkit-user-drag:none;color:#000;user-select:text}*{--color-danger-fg:#cf222e;--color-border-default:#d0d7de;--color-border-muted:#d8dee4;--color-canvas-subtle:#f6f8fa;--color-fg-default:#24292f;--color-fg-muted:#57606a;--color-neutral-muted:rgba(175,184,193,.2);--color-accent-emphasis:#0969da;--color-accent-fg:#0969da}code{font-family:ui-monospace,SFMono-Regular,SF Mono,Menlo,Consolas,Liberation Mono,monospace}.markdown-body{word-wrap:break-word;font-family:-apple-system,BlinkMacSystemFont,Segoe UI,Noto Sans,Helvetica,Arial,sans-serif,Apple Color Emoji,Segoe UI Emoji;font-size:16px;line-height:1.5}.markdown-body:after,.markdown-body:before{content:"";display:table}.markdown-body:after{clear:both}.markdown-body>:first-child{margin-top:0!important}.markdown-body>:last-child{margin-bottom:0!important}.markdown-body a:not([href]){color:inherit;text-decoration:none}.markdown-body .absent{color:var(--fgColor-danger,var(--color-danger-fg))}.markdown-body .anchor{float:left;line-height:1;margin-left:-20px;padding-right:4px}.markdown-body .anchor:focus{outline:none}.markdown-body blockquote,.markdown-body details,.markdown-body dl,.markdown-body ol,.markdown-body p,.markdown-body pre,.markdown-body table,.markdown-body ul{margin-bottom:16px;margin-top:0}.markdown-body hr{background-color:var(--borderColor-default,var(--color-border-default));border:0;height:.25em;margin:24px 0;padding:0}.markdown-body blockquote{border-left:.25em solid var(--borderColor-default,var(--color-border-default));color:var(--fgColor-muted,var(--color-fg-muted));padding:0 1em}.markdown-body blockquote>:first-child{margin-top:0}.markdown-body blockquote>:last-child{margin-bottom:0}.markdown-body h1,.markdown-body h2,.markdown-body h3,.markdown-body h4,.markdown-body h5,.markdown-body h6{font-weight:var(--base-text-weight-semibold,600);line-height:1.25;margin-bottom:16px;margin-top:24px}.markdown-body h1 .octicon-link,.markdown-body h2 .octicon-link,.markdown-body h3 .octicon-link,.markdown-body h4 .octicon-link,.markdown-body h5 .octicon-link,.markdown-body h6 .octicon-link{color:var(--fgColor-default,var(--color-fg-default));vertical-align:middle;visibility:hidden}.markdown-body h1:hover .anchor,.markdown-body h2:hover .anchor,.markdown-body h3:hover .anchor,.markdown-body h4:hover .anchor,.markdown-body h5:hover .anchor,.markdown-body h6:hover .anchor{text-decoration:none}.markdown-body h1:hover .anchor .octicon-link,.markdown-body h2:hover .anchor .octicon-link,.markdown-body h3:hover .anchor .octicon-link,.markdown-body h4:hover .anchor .octicon-link,.markdown-body h5:hover .anchor .octicon-link,.markdown-body h6:hover .anchor .octicon-link{visibility:visible}.markdown-body h1 code,.markdown-body h1 tt,.markdown-body h2 code,.markdown-body h2 tt,.markdown-body h3 code,.markdown-body h3 tt,.markdown-body h4 code,.markdown-body h4 tt,.markdown-body h5 code,.markdown-body h5 tt,.markdown-body h6 code,.markdown-body h6 tt{font-size:inherit;padding:0 .2em}.markdown-body h1{font-size:2em}.markdown-body h1,.markdown-body h2{border-bottom:1px solid var(--borderColor-muted,var(--color-border-muted));padding-bottom:.3em}.markdown-body h2{font-size:1.5em}.markdown-body h3{font-size:1.25em}.markdown-body h4{font-size:1em}.markdown-body h5{font-size:.875em}.markdown-body h6{color:var(--fgColor-muted,var(--color-fg-muted));font-size:.85em}.markdown-body summary h1,.markdown-body summary h2,.markdown-body summary h3,.markdown-body summary h4,.markdown-body summary h5,.markdown-body summary h6{display:inline-block}.markdown-body summary h1 .anchor,.markdown-body summary h2 .anchor,.markdown-body summary h3 .anchor,.markdown-body summary h4 .anchor,.markdown-body summary h5 .anchor,.markdown-body summary h6 .anchor{margin-left:-40px}.markdown-body summary h1,.markdown-body summary h2{border-bottom:0;padding-bottom:0}.markdown-body ol,.markdown-body ul{padding-left:2em}.markdown-body ol.no-list,.markdown-body ul.no-list{list-style-type:none;padding:0}.markdown-body ol[type="a s"]{list-style-type:lower-alpha}.markdown-body ol[type="A s"]{list-style-type:upper-alpha}.markdown-body ol[type="i s"]{list-style-type:lower-roman}.markdown-body ol[type="I s"]{list-style-type:upper-roman}.markdown-body div>ol:not([type]),.markdown-body ol[type="1"]{list-style-type:decimal}.markdown-body ol ol,.markdown-body ol ul,.markdown-body ul ol,.markdown-body ul ul{margin-bottom:0;margin-top:0}.markdown-body li>p{margin-top:16px}.markdown-body li+li{margin-top:.25em}.markdown-body dl{padding:0}.markdown-body dl dt{font-size:1em;font-style:italic;font-weight:var(--base-text-weight-semibold,600);margin-top:16px;padding:0}.markdown-body dl dd{margin-bottom:16px;padding:0 16px}.markdown-body table{display:block;max-width:100%;overflow:auto;width:100%;width:max-content}.markdown-body table th{font-weight:var(--base-text-weight-semibold,600)}.markdown-body table td,.markdown-body table th{border:1px solid var(--borderColor-default,var(--color-border-default));padding:6px 13px}.markdown-body table td>:last-child{margin-bottom:0}.markdown-body table tr{background-color:var(--bgColor-default,var(--color-canvas-default));border-top:1px solid var(--borderColor-muted,var(--color-border-muted))}.markdown-body table tr:nth-child(2n){background-color:var(--bgColor-muted,var(--color-canvas-subtle))}.markdown-body table img{background-color:transparent}.markdown-body img{background-color:var(--bgColor-default,var(--color-canvas-default));box-sizing:content-box;max-width:100%}.markdown-body img[align=right]{padding-left:20px}.markdown-body img[align=left]{padding-right:20px}.markdown-body .emoji{background-color:transparent;max-width:none;vertical-align:text-top}.markdown-body span.frame{display:block;overflow:hidden}.markdown-body span.frame>span{border:1px solid var(--borderColor-default,var(--color-border-default));display:block;float:left;margin:13px 0 0;overflow:hidden;padding:7px;width:auto}.markdown-body span.frame span img{display:block;float:left}.markdown-body span.frame span span{clear:both;color:var(--fgColor-default,var(--color-fg-default));display:block;padding:5px 0 0}.markdown-body span.align-center{clear:both;display:block;overflow:hidden}.markdown-body span.align-center>span{display:block;margin:13px auto 0;overflow:hidden;text-align:center}.markdown-body span.align-center span img{margin:0 auto;text-align:center}.markdown-body span.align-right{clear:both;display:block;overflow:hidden}.markdown-body span.align-right>span{display:block;margin:13px 0 0;overflow:hidden;text-align:right}.markdown-body span.align-right span img{margin:0;text-align:right}.markdown-body span.float-left{display:block;float:left;margin-right:13px;overflow:hidden}.markdown-body span.float-left span{margin:13px 0 0}.markdown-body span.float-right{display:block;float:right;margin-left:13px;overflow:hidden}.markdown-body span.float-right>span{display:block;margin:13px auto 0;overflow:hidden;text-align:right}.markdown-body code,.markdown-body tt{background-color:var(--bgColor-neutral-muted,var(--color-neutral-muted));border-radius:6px;font-size:85%;margin:0;padding:.2em .4em;white-space:break-spaces}.markdown-body code br,.markdown-body tt br{display:none}.markdown-body del code{text-decoration:inherit}.markdown-body samp{font-size:85%}.markdown-body pre{word-wrap:normal}.markdown-body pre code{font-size:100%}.markdown-body pre>code{background:transparent;border:0;margin:0;padding:0;white-space:pre;word-break:normal}.markdown-body .highlight{margin-bottom:16px}.markdown-body .highlight pre{margin-bottom:0;word-break:normal}.markdown-body .highlight pre,.markdown-body pre{background-color:var(--bgColor-muted,var(--color-canvas-subtle));border-radius:6px;color:var(--fgColor-default,var(--color-fg-default));font-size:85%;line-height:1.45;overflow:auto;padding:16px}.markdown-body pre code,.markdown-body pre tt{word-wrap:normal;background-color:transparent;border:0;display:inline;line-height:inherit;margin:0;max-width:auto;overflow:visible;padding:0}.markdown-body .csv-data td,.markdown-body .csv-data th{font-size:12px;line-height:1;overflow:hidden;padding:5px;text-align:left;white-space:nowrap}.markdown-body .csv-data .blob-num{background:var(--bgColor-default,var(--color-canvas-default));border:0;padding:10px 8px 9px;text-align:right}.markdown-body .csv-data tr{border-top:0}.markdown-body .csv-data th{background:var(--bgColor-muted,var(--color-canvas-subtle));border-top:0;font-weight:var(--base-text-weight-semibold,600)}.markdown-body [data-footnote-ref]:before{content:"["}.markdown-body [data-footnote-ref]:after{content:"]"}.markdown-body .footnotes{border-top:1px solid var(--borderColor-default,var(--color-border-default));color:var(--fgColor-muted,var(--color-fg-muted));font-size:12px}.markdown-body .footnotes ol{padding-left:16px}.markdown-body .footnotes ol ul{display:inline-block;margin-top:16px;padding-left:16px}.markdown-body .footnotes li{position:relative}.markdown-body .footnotes li:target:before{border:2px solid var(--borderColor-accent-emphasis,var(--color-accent-emphasis));border-radius:6px;bottom:-8px;content:"";left:-24px;pointer-events:none;position:absolute;right:-8px;top:-8px}.markdown-body .footnotes li:target{color:var(--fgColor-default,var(--color-fg-default))}.markdown-body .footnotes .data-footnote-backref g-emoji{font-family:monospace}.Link{color:var(--fgColor-accent,var(--color-accent-fg));-webkit-text-decoration:none;text-decoration:none}.Link:hover{cursor:pointer}.Link:focus,.Link:hover{-webkit-text-decoration:underline;text-decoration:underline}.Link:focus,.Link:focus-visible{outline-offset:0}.Link--underline{-webkit-text-decoration:underline;text-decoration:underline}.Link--primary{color:var(--fgColor-default,var(--color-fg-default))!important}.Link--primary:hover{color:var(--fgColor-accent,var(--color-accent-fg))!important}.Link--secondary{color:var(--fgColor-muted,var(--color-fg-muted))!important}.Link--secondary:hover{color:var(--fgColor-accent,var(--color-accent-fg))!important}.Link--muted{color:var(--fgColor-muted,var(--color-fg-muted))!important}.Link--muted:hover{-webkit-text-decoration:none;text-decoration:none}.Link--muted:hover,.Link--onHover:hover{color:var(--fgColor-accent,var(--color-accent-fg))!important}.Link--onHover:hover{cursor:pointer;-webkit-text-decoration:underline;text-decoration:underline}.Link--muted:hover [class*=color-fg],.Link--primary:hover [class*=color-fg],.Link--secondary:hover [class*=color-fg]{color:inherit!important}
+@media (width <= 1200px){.header-list,.index-menu{display:none}.left-side,.right-side{border:none}}*,:after,:before{box-sizing:border-box}body,html{height:100%}body{font-family:Meiryo,Hiragino Fixed,sans-serif;margin:0}body::-webkit-scrollbar-thumb{background-clip:content-box;background-color:grey;border:4px solid transparent;border-radius:8px;height:64px}body::-webkit-scrollbar{width:16px}.container{display:grid;grid-template-columns:1fr 1000px 1fr;grid-template-rows:1fr auto}.left-side{grid-column:1/2;grid-row:1/3;height:max-content;min-height:100%}.left-side:has(.index-menu){border-right:1px solid var(--color-border-muted)}.right-side{grid-column:3/4;grid-row:1/3}.right-side:has(.header-list){border-left:1px solid var(--color-border-muted)}.main{grid-row:1/2}.main,footer{grid-column:2/3;padding:1.25rem}footer{display:block;font-size:16px;grid-row:2/3;text-align:center}a{text-decoration:none}table{border-collapse:collapse}.index-menu p{font-size:14px;margin-bottom:0;margin-left:17px;margin-top:4px}.index-menu p:last-child{margin-bottom:4px}.index-menu{margin-left:auto;max-height:100vh;overflow-y:auto;padding:1.25rem;position:sticky;top:0;width:max-content}:is(.index-menu,.header-list) a{color:#000}.index-menu a.current{font-weight:700}.header-list{padding:1.25rem;position:sticky;top:0}.header-list p{font-size:14px;margin-bottom:4px;margin-top:0}.header-list .h2{margin-left:14px}.header-list .h3{margin-left:28px}.header-list .h4{margin-left:42px}.header-list .h5{margin-left:56px}.header-list .h6{margin-left:70px}.header-list :is(ul,ol){font-size:14px;margin:0;padding-left:14px}.header-list>:is(ul,ol){padding-left:0}.header-list ul{list-style:none}.header-list ol{list-style-position:inside}.header-list li{margin-bottom:4px}.header-list a.active{font-weight:700}.markdown-body img{display:block;margin:auto}.markdown-body :is(h1,h2,h3,h4,h5,h6) a{-webkit-user-drag:none;color:#000;user-select:text}*{--color-danger-fg:#cf222e;--color-border-default:#d0d7de;--color-border-muted:#d8dee4;--color-canvas-subtle:#f6f8fa;--color-fg-default:#24292f;--color-fg-muted:#57606a;--color-neutral-muted:rgba(175,184,193,.2);--color-accent-emphasis:#0969da;--color-accent-fg:#0969da}code{font-family:ui-monospace,SFMono-Regular,SF Mono,Menlo,Consolas,Liberation Mono,monospace}.markdown-body{word-wrap:break-word;font-family:-apple-system,BlinkMacSystemFont,Segoe UI,Noto Sans,Helvetica,Arial,sans-serif,Apple Color Emoji,Segoe UI Emoji;font-size:16px;line-height:1.5}.markdown-body:after,.markdown-body:before{content:"";display:table}.markdown-body:after{clear:both}.markdown-body>:first-child{margin-top:0!important}.markdown-body>:last-child{margin-bottom:0!important}.markdown-body a:not([href]){color:inherit;text-decoration:none}.markdown-body .absent{color:var(--fgColor-danger,var(--color-danger-fg))}.markdown-body .anchor{float:left;line-height:1;margin-left:-20px;padding-right:4px}.markdown-body .anchor:focus{outline:none}.markdown-body blockquote,.markdown-body details,.markdown-body dl,.markdown-body ol,.markdown-body p,.markdown-body pre,.markdown-body table,.markdown-body ul{margin-bottom:16px;margin-top:0}.markdown-body hr{background-color:var(--borderColor-default,var(--color-border-default));border:0;height:.25em;margin:24px 0;padding:0}.markdown-body blockquote{border-left:.25em solid var(--borderColor-default,var(--color-border-default));color:var(--fgColor-muted,var(--color-fg-muted));padding:0 1em}.markdown-body blockquote>:first-child{margin-top:0}.markdown-body blockquote>:last-child{margin-bottom:0}.markdown-body h1,.markdown-body h2,.markdown-body h3,.markdown-body h4,.markdown-body h5,.markdown-body h6{font-weight:var(--base-text-weight-semibold,600);line-height:1.25;margin-bottom:16px;margin-top:24px}.markdown-body h1 .octicon-link,.markdown-body h2 .octicon-link,.markdown-body h3 .octicon-link,.markdown-body h4 .octicon-link,.markdown-body h5 .octicon-link,.markdown-body h6 .octicon-link{color:var(--fgColor-default,var(--color-fg-default));vertical-align:middle;visibility:hidden}.markdown-body h1:hover .anchor,.markdown-body h2:hover .anchor,.markdown-body h3:hover .anchor,.markdown-body h4:hover .anchor,.markdown-body h5:hover .anchor,.markdown-body h6:hover .anchor{text-decoration:none}.markdown-body h1:hover .anchor .octicon-link,.markdown-body h2:hover .anchor .octicon-link,.markdown-body h3:hover .anchor .octicon-link,.markdown-body h4:hover .anchor .octicon-link,.markdown-body h5:hover .anchor .octicon-link,.markdown-body h6:hover .anchor .octicon-link{visibility:visible}.markdown-body h1 code,.markdown-body h1 tt,.markdown-body h2 code,.markdown-body h2 tt,.markdown-body h3 code,.markdown-body h3 tt,.markdown-body h4 code,.markdown-body h4 tt,.markdown-body h5 code,.markdown-body h5 tt,.markdown-body h6 code,.markdown-body h6 tt{font-size:inherit;padding:0 .2em}.markdown-body h1{font-size:2em}.markdown-body h1,.markdown-body h2{border-bottom:1px solid var(--borderColor-muted,var(--color-border-muted));padding-bottom:.3em}.markdown-body h2{font-size:1.5em}.markdown-body h3{font-size:1.25em}.markdown-body h4{font-size:1em}.markdown-body h5{font-size:.875em}.markdown-body h6{color:var(--fgColor-muted,var(--color-fg-muted));font-size:.85em}.markdown-body summary h1,.markdown-body summary h2,.markdown-body summary h3,.markdown-body summary h4,.markdown-body summary h5,.markdown-body summary h6{display:inline-block}.markdown-body summary h1 .anchor,.markdown-body summary h2 .anchor,.markdown-body summary h3 .anchor,.markdown-body summary h4 .anchor,.markdown-body summary h5 .anchor,.markdown-body summary h6 .anchor{margin-left:-40px}.markdown-body summary h1,.markdown-body summary h2{border-bottom:0;padding-bottom:0}.markdown-body ol,.markdown-body ul{padding-left:2em}.markdown-body ol.no-list,.markdown-body ul.no-list{list-style-type:none;padding:0}.markdown-body ol[type="a s"]{list-style-type:lower-alpha}.markdown-body ol[type="A s"]{list-style-type:upper-alpha}.markdown-body ol[type="i s"]{list-style-type:lower-roman}.markdown-body ol[type="I s"]{list-style-type:upper-roman}.markdown-body div>ol:not([type]),.markdown-body ol[type="1"]{list-style-type:decimal}.markdown-body ol ol,.markdown-body ol ul,.markdown-body ul ol,.markdown-body ul ul{margin-bottom:0;margin-top:0}.markdown-body li>p{margin-top:16px}.markdown-body li+li{margin-top:.25em}.markdown-body dl{padding:0}.markdown-body dl dt{font-size:1em;font-style:italic;font-weight:var(--base-text-weight-semibold,600);margin-top:16px;padding:0}.markdown-body dl dd{margin-bottom:16px;padding:0 16px}.markdown-body table{display:block;max-width:100%;overflow:auto;width:100%;width:max-content}.markdown-body table th{font-weight:var(--base-text-weight-semibold,600)}.markdown-body table td,.markdown-body table th{border:1px solid var(--borderColor-default,var(--color-border-default));padding:6px 13px}.markdown-body table td>:last-child{margin-bottom:0}.markdown-body table tr{background-color:var(--bgColor-default,var(--color-canvas-default));border-top:1px solid var(--borderColor-muted,var(--color-border-muted))}.markdown-body table tr:nth-child(2n){background-color:var(--bgColor-muted,var(--color-canvas-subtle))}.markdown-body table img{background-color:transparent}.markdown-body img{background-color:var(--bgColor-default,var(--color-canvas-default));box-sizing:content-box;max-width:100%}.markdown-body img[align=right]{padding-left:20px}.markdown-body img[align=left]{padding-right:20px}.markdown-body .emoji{background-color:transparent;max-width:none;vertical-align:text-top}.markdown-body span.frame{display:block;overflow:hidden}.markdown-body span.frame>span{border:1px solid var(--borderColor-default,var(--color-border-default));display:block;float:left;margin:13px 0 0;overflow:hidden;padding:7px;width:auto}.markdown-body span.frame span img{display:block;float:left}.markdown-body span.frame span span{clear:both;color:var(--fgColor-default,var(--color-fg-default));display:block;padding:5px 0 0}.markdown-body span.align-center{clear:both;display:block;overflow:hidden}.markdown-body span.align-center>span{display:block;margin:13px auto 0;overflow:hidden;text-align:center}.markdown-body span.align-center span img{margin:0 auto;text-align:center}.markdown-body span.align-right{clear:both;display:block;overflow:hidden}.markdown-body span.align-right>span{display:block;margin:13px 0 0;overflow:hidden;text-align:right}.markdown-body span.align-right span img{margin:0;text-align:right}.markdown-body span.float-left{display:block;float:left;margin-right:13px;overflow:hidden}.markdown-body span.float-left span{margin:13px 0 0}.markdown-body span.float-right{display:block;float:right;margin-left:13px;overflow:hidden}.markdown-body span.float-right>span{display:block;margin:13px auto 0;overflow:hidden;text-align:right}.markdown-body code,.markdown-body tt{background-color:var(--bgColor-neutral-muted,var(--color-neutral-muted));border-radius:6px;font-size:85%;margin:0;padding:.2em .4em;white-space:break-spaces}.markdown-body code br,.markdown-body tt br{display:none}.markdown-body del code{text-decoration:inherit}.markdown-body samp{font-size:85%}.markdown-body pre{word-wrap:normal}.markdown-body pre code{font-size:100%}.markdown-body pre>code{background:transparent;border:0;margin:0;padding:0;white-space:pre;word-break:normal}.markdown-body .highlight{margin-bottom:16px}.markdown-body .highlight pre{margin-bottom:0;word-break:normal}.markdown-body .highlight pre,.markdown-body pre{background-color:var(--bgColor-muted,var(--color-canvas-subtle));border-radius:6px;color:var(--fgColor-default,var(--color-fg-default));font-size:85%;line-height:1.45;overflow:auto;padding:16px}.markdown-body pre code,.markdown-body pre tt{word-wrap:normal;background-color:transparent;border:0;display:inline;line-height:inherit;margin:0;max-width:auto;overflow:visible;padding:0}.markdown-body .csv-data td,.markdown-body .csv-data th{font-size:12px;line-height:1;overflow:hidden;padding:5px;text-align:left;white-space:nowrap}.markdown-body .csv-data .blob-num{background:var(--bgColor-default,var(--color-canvas-default));border:0;padding:10px 8px 9px;text-align:right}.markdown-body .csv-data tr{border-top:0}.markdown-body .csv-data th{background:var(--bgColor-muted,var(--color-canvas-subtle));border-top:0;font-weight:var(--base-text-weight-semibold,600)}.markdown-body [data-footnote-ref]:before{content:"["}.markdown-body [data-footnote-ref]:after{content:"]"}.markdown-body .footnotes{border-top:1px solid var(--borderColor-default,var(--color-border-default));color:var(--fgColor-muted,var(--color-fg-muted));font-size:12px}.markdown-body .footnotes ol{padding-left:16px}.markdown-body .footnotes ol ul{display:inline-block;margin-top:16px;padding-left:16px}.markdown-body .footnotes li{position:relative}.markdown-body .footnotes li:target:before{border:2px solid var(--borderColor-accent-emphasis,var(--color-accent-emphasis));border-radius:6px;bottom:-8px;content:"";left:-24px;pointer-events:none;position:absolute;right:-8px;top:-8px}.markdown-body .footnotes li:target{color:var(--fgColor-default,var(--color-fg-default))}.markdown-body .footnotes .data-footnote-backref g-emoji{font-family:monospace}.Link{color:var(--fgColor-accent,var(--color-accent-fg));-webkit-text-decoration:none;text-decoration:none}.Link:hover{cursor:pointer}.Link:focus,.Link:hover{-webkit-text-decoration:underline;text-decoration:underline}.Link:focus,.Link:focus-visible{outline-offset:0}.Link--underline{-webkit-text-decoration:underline;text-decoration:underline}.Link--primary{color:var(--fgColor-default,var(--color-fg-default))!important}.Link--primary:hover{color:var(--fgColor-accent,var(--color-accent-fg))!important}.Link--secondary{color:var(--fgColor-muted,var(--color-fg-muted))!important}.Link--secondary:hover{color:var(--fgColor-accent,var(--color-accent-fg))!important}.Link--muted{color:var(--fgColor-muted,var(--color-fg-muted))!important}.Link--muted:hover{-webkit-text-decoration:none;text-decoration:none}.Link--muted:hover,.Link--onHover:hover{color:var(--fgColor-accent,var(--color-accent-fg))!important}.Link--onHover:hover{cursor:pointer;-webkit-text-decoration:underline;text-decoration:underline}.Link--muted:hover [class*=color-fg],.Link--primary:hover [class*=color-fg],.Link--secondary:hover [class*=color-fg]{color:inherit!important}.code-block{margin-bottom:16px;position:relative}.code-block pre{margin-bottom:0}.code-title{background-color:var(--bgColor-muted,var(--color-canvas-subtle));border-bottom:1px solid var(--borderColor-default,var(--color-border-default));border-radius:6px 6px 0 0;font-family:ui-monospace,SFMono-Regular,SF Mono,Menlo,Consolas,Liberation Mono,monospace;font-size:85%;padding:8px 16px}.code-title+pre{border-radius:0 0 6px 6px}pre .line{display:flex}pre .ln{color:#6e7781;margin-right:1em;min-width:2em;text-align:right;-webkit-user-select:none;user-select:none}pre .hl{background-color:#fff8c5}pre .diff-add{background-color:#dafbe1}pre .diff-remove{background-color:#ffebe9}.copy-button{background-color:var(--bgColor-default,var(--color-canvas-default));border:1px solid var(--borderColor-default,var(--color-border-default));border-radius:6px;cursor:pointer;font-size:12px;opacity:0;padding:2px 8px;position:absolute;right:8px;top:8px}.code-block:hover .copy-button,.copy-button:focus{opacity:1}.shortcode-video{aspect-ratio:16/9;margin-bottom:16px}.shortcode-video iframe{border:0;height:100%;width:100%}.markdown-body video{max-width:100%}.tabs{margin-bottom:16px}.tab-bar{border-bottom:1px solid var(--borderColor-default,var(--color-border-default));display:flex;gap:4px;margin-bottom:8px}.tab-button{background:none;border:0;border-bottom:2px solid transparent;color:inherit;cursor:pointer;font:inherit;padding:6px 12px}.tab-button.active{border-bottom-color:var(--fgColor-accent,var(--color-accent-fg));font-weight:600}.tabs-ready details.tab>summary{display:none}.callout{border-left:.25em solid var(--callout-color);margin-bottom:16px;padding:8px 16px}.callout>:last-child{margin-bottom:0}.markdown-body .callout-title{color:var(--callout-color);font-weight:600;margin-bottom:8px}.callout-note{--callout-color:#0969da}.callout-tip{--callout-color:#1a7f37}.callout-important{--callout-color:#8250df}.callout-warning{--callout-color:#9a6700}.callout-caution{--callout-color:#cf222e}.callout-title:before{display:inline-block;margin-right:.5em;text-align:center;width:1em}.callout-note .callout-title:before{content:"\2139"}.callout-tip .callout-title:before{content:"\2726"}.callout-important .callout-title:before{content:"\2757"}.callout-warning .callout-title:before{content:"\26A0"}.callout-caution .callout-title:before{content:"\26D4"}math[display=block]{display:block;margin:1em 0;overflow-x:auto;overflow-y:hidden}merror{color:#c00}.diagram{margin:1em 0;overflow-x:auto;text-align:center}.diagram svg{height:auto;max-width:100%}.vertical{font-feature-settings:"vpal";height:calc(100vh - 8rem);line-height:1.9;max-width:100%;min-height:24em;overflow-x:auto;overflow-y:hidden;overscroll-behavior-x:contain;text-orientation:mixed;-webkit-writing-mode:vertical-rl;writing-mode:vertical-rl}.vertical h1,.vertical h2,.vertical h3,.vertical h4,.vertical h5,.vertical h6{border-bottom:none;border-left:1px solid var(--borderColor-muted,var(--color-border-muted));margin:0 0 0 1em;padding:0 0 0 .3em}.vertical blockquote,.vertical dl,.vertical ol,.vertical p,.vertical ul{margin:0 0 0 1em}.vertical .tcy{-webkit-text-combine:horizontal;text-combine-upright:all}.vertical .callout,.vertical .code-block,.vertical .diagram,.vertical figure,.vertical img,.vertical math[display=block],.vertical pre,.vertical table{max-height:100%;overflow:auto;-webkit-writing-mode:horizontal-tb;writing-mode:horizontal-tb}.vertical code,.vertical kbd{text-orientation:mixed}.vertical ruby rt{font-feature-settings:normal}.language-switcher{display:inline-flex;gap:1rem;margin-left:1rem}.language-switcher .current{font-weight:700;text-decoration:none}.version-switcher{display:inline-flex;gap:1rem;margin-left:1rem}.version-switcher .current{font-weight:700;text-decoration:none}.version-banner{background-color:#fff8c5;border-left:4px solid #d4a72c;margin-bottom:1rem;padding:.5rem 1rem}
 `
@@ -25,7 +25,7 @@ func CreateWriteTask(outputDirDir, fileName string) func() error {
 		}
 		defer file.Close()
 
-		_, err = file.WriteString(CSS_CONTENT)
+		_, err = file.WriteString(content)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -33,6 +33,15 @@ func CreateWriteTask(outputDirDir, fileName string) func() error {
 	}
 }
 
+// content is the CSS written by CreateWriteTask. It is CSS_CONTENT followed by the CSS added by Append.
+var content = CSS_CONTENT
+
+// Append adds CSS such as the stylesheet of syntax highlighting to the end of the CSS file.
+// It must be called before CreateWriteTask and Version so that the version reflects the added CSS.
+func Append(css string) {
+	content += "\n" + css
+}
+
 func Version() string {
-	return uuid.NewSHA1(uuid.Nil, []byte(CSS_CONTENT)).String()
+	return uuid.NewSHA1(uuid.Nil, []byte(content)).String()
 }
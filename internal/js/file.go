@@ -0,0 +1,38 @@
+package js
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// CreateWriteTask returns a closure function that when executed, creates or ensures the existence
+// of the specified output directory and writes JavaScript content to a file within that directory.
+// The function takes two parameters: 'outputDir' is the path to the output directory where the JavaScript file
+// should be created, and 'fileName' is the name of the JavaScript file to be created.
+func CreateWriteTask(outputDir, fileName string) func() error {
+	return func() error {
+		err := os.MkdirAll(outputDir, os.ModePerm)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		jsFileName := filepath.Join(outputDir, fileName)
+		file, err := os.Create(jsFileName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer file.Close()
+
+		_, err = file.WriteString(JS_CONTENT)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return nil
+	}
+}
+
+func Version() string {
+	return uuid.NewSHA1(uuid.Nil, []byte(JS_CONTENT)).String()
+}
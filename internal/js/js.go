@@ -0,0 +1,151 @@
+package js
+
+// JS_CONTENT is the script shared by every generated page.
+// It keeps the current page of the index menu in view, and highlights the current section
+// in the header list if the header list has the "scroll-spy" class.
+// It also adds copy buttons to the code blocks that have the "copy" class, and switches the tabs of the tabs shortcode.
+// If the diagram script has loaded mermaid, it renders the mermaid diagrams.
+// On a vertical page, the header list follows the horizontal scroll of the body, and the mouse wheel scrolls the body horizontally.
+const JS_CONTENT = `
+(function () {
+  var menu = document.querySelector(".index-menu");
+  var current = menu && menu.querySelector('[aria-current="page"]');
+  if (current) {
+    menu.scrollTop = current.offsetTop - menu.clientHeight / 2;
+  }
+})();
+
+(function () {
+  var nav = document.querySelector(".header-list.scroll-spy");
+  if (!nav) {
+    return;
+  }
+  var items = [];
+  nav.querySelectorAll('a[href^="#"]').forEach(function (link) {
+    var heading = document.getElementById(decodeURIComponent(link.getAttribute("href").slice(1)));
+    if (heading) {
+      items.push({ link: link, heading: heading });
+    }
+  });
+  var vertical = document.querySelector(".vertical");
+  var active = null;
+  var ticking = false;
+  function passed(heading) {
+    if (vertical) {
+      return heading.getBoundingClientRect().right >= vertical.getBoundingClientRect().right - 80;
+    }
+    return heading.getBoundingClientRect().top <= 80;
+  }
+  function update() {
+    ticking = false;
+    var current = null;
+    items.forEach(function (item) {
+      if (passed(item.heading)) {
+        current = item;
+      }
+    });
+    if (current === active) {
+      return;
+    }
+    if (active) {
+      active.link.classList.remove("active");
+    }
+    if (current) {
+      current.link.classList.add("active");
+    }
+    active = current;
+  }
+  (vertical || window).addEventListener("scroll", function () {
+    if (!ticking) {
+      ticking = true;
+      window.requestAnimationFrame(update);
+    }
+  }, { passive: true });
+  update();
+})();
+
+(function () {
+  var vertical = document.querySelector(".vertical");
+  if (!vertical) {
+    return;
+  }
+  vertical.addEventListener("wheel", function (event) {
+    if (event.deltaX !== 0 || event.deltaY === 0 || event.target.closest("pre, table")) {
+      return;
+    }
+    event.preventDefault();
+    vertical.scrollLeft -= event.deltaY;
+  }, { passive: false });
+})();
+
+(function () {
+  document.querySelectorAll(".code-block.copy").forEach(function (block) {
+    var code = block.querySelector("pre code");
+    if (!code || !navigator.clipboard) {
+      return;
+    }
+    var button = document.createElement("button");
+    button.type = "button";
+    button.className = "copy-button";
+    button.textContent = "Copy";
+    button.addEventListener("click", function () {
+      var lines = code.querySelectorAll(".cl");
+      var text = lines.length > 0
+        ? Array.prototype.map.call(lines, function (line) { return line.textContent; }).join("")
+        : code.textContent;
+      navigator.clipboard.writeText(text).then(function () {
+        button.textContent = "Copied";
+        setTimeout(function () {
+          button.textContent = "Copy";
+        }, 2000);
+      });
+    });
+    block.appendChild(button);
+  });
+})();
+
+(function () {
+  document.querySelectorAll(".tabs").forEach(function (tabs) {
+    var panels = Array.prototype.filter.call(tabs.children, function (child) {
+      return child.matches("details.tab");
+    });
+    if (panels.length === 0) {
+      return;
+    }
+    var bar = document.createElement("div");
+    bar.className = "tab-bar";
+    bar.setAttribute("role", "tablist");
+    var buttons = panels.map(function (panel, index) {
+      var button = document.createElement("button");
+      button.type = "button";
+      button.className = "tab-button";
+      button.setAttribute("role", "tab");
+      button.textContent = panel.querySelector("summary").textContent;
+      button.addEventListener("click", function () {
+        select(index);
+      });
+      bar.appendChild(button);
+      return button;
+    });
+    function select(selected) {
+      panels.forEach(function (panel, index) {
+        panel.open = true;
+        panel.hidden = index !== selected;
+        buttons[index].classList.toggle("active", index === selected);
+        buttons[index].setAttribute("aria-selected", String(index === selected));
+      });
+    }
+    tabs.insertBefore(bar, tabs.firstChild);
+    tabs.classList.add("tabs-ready");
+    select(0);
+  });
+})();
+(function () {
+  var diagrams = document.querySelectorAll("pre.diagram-mermaid");
+  if (diagrams.length === 0 || !window.mermaid) {
+    return;
+  }
+  window.mermaid.initialize({ startOnLoad: false });
+  window.mermaid.run({ nodes: diagrams });
+})();
+`
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// calloutTypes holds the types of callouts.
+var calloutTypes = []string{"note", "tip", "important", "warning", "caution"}
+
+// calloutType returns the type of a callout. An unknown type is "note".
+func calloutType(t string) string {
+	t = strings.ToLower(t)
+	for _, calloutType := range calloutTypes {
+		if t == calloutType {
+			return t
+		}
+	}
+	return calloutTypes[0]
+}
+
+// calloutTitle returns the default title of a type of callout, such as "Note".
+func calloutTitle(t string) string {
+	t = calloutType(t)
+	return strings.ToUpper(t[:1]) + t[1:]
+}
+
+// calloutClassPattern matches the class of a callout.
+var calloutClassPattern = regexp.MustCompile(`^callout callout-(note|tip|important|warning|caution)$`)
+
+// isCalloutType determines if t is one of the types of callouts.
+func isCalloutType(t string) bool {
+	return calloutType(t) == strings.ToLower(t)
+}
+
+// KindCallout is the NodeKind of Callout.
+var KindCallout = ast.NewNodeKind("Callout")
+
+// Callout is a block such as a note or a warning that is rendered with its title.
+type Callout struct {
+	ast.BaseBlock
+	CalloutType string
+	Title       string
+	// fence is the opening fence such as ":::" of a fenced callout.
+	fence string
+}
+
+func (n *Callout) Kind() ast.NodeKind {
+	return KindCallout
+}
+
+func (n *Callout) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"CalloutType": n.CalloutType, "Title": n.Title}, nil)
+}
+
+// newCallout creates a callout. If title is empty, the title is the name of the type.
+func newCallout(t, title string) *Callout {
+	if title == "" {
+		title = calloutTitle(t)
+	}
+	return &Callout{CalloutType: calloutType(t), Title: title}
+}
+
+// alertRe matches the first line of a GitHub-style alert such as "[!NOTE]".
+var alertRe = regexp.MustCompile(`^\[!([a-zA-Z]+)\][ \t]*(.*?)\s*$`)
+
+// nodeStart returns the offset of the first text in a node, or -1 if it has no text.
+func nodeStart(node ast.Node) int {
+	start := -1
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering {
+			start = t.Segment.Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return start
+}
+
+// calloutTransformer converts GitHub-style alerts such as "> [!NOTE]" into callouts.
+type calloutTransformer struct{}
+
+func (t calloutTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	blockquotes := []*ast.Blockquote{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if blockquote, ok := n.(*ast.Blockquote); ok && entering {
+			blockquotes = append(blockquotes, blockquote)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, blockquote := range blockquotes {
+		paragraph, ok := blockquote.FirstChild().(*ast.Paragraph)
+		if !ok || paragraph.Lines().Len() == 0 {
+			continue
+		}
+		firstLine := paragraph.Lines().At(0)
+		m := alertRe.FindSubmatch(firstLine.Value(source))
+		if m == nil || !isCalloutType(string(m[1])) {
+			continue
+		}
+		// The inline nodes in the first line are the marker and the title.
+		for child := paragraph.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			if start := nodeStart(child); start >= 0 && start >= firstLine.Stop {
+				break
+			}
+			paragraph.RemoveChild(paragraph, child)
+			child = next
+		}
+		if !paragraph.HasChildren() {
+			blockquote.RemoveChild(blockquote, paragraph)
+		}
+		callout := newCallout(string(m[1]), string(m[2]))
+		for child := blockquote.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			callout.AppendChild(callout, child)
+			child = next
+		}
+		blockquote.Parent().ReplaceChild(blockquote.Parent(), blockquote, callout)
+	}
+}
+
+var (
+	// calloutFenceOpenRe matches the opening fence of a callout such as ":::note Title".
+	calloutFenceOpenRe = regexp.MustCompile(`^ {0,3}(:{3,})[ \t]*([a-zA-Z]+)(?:[ \t]+(.*?))?\s*$`)
+	// calloutFenceCloseRe matches the closing fence of a callout.
+	calloutFenceCloseRe = regexp.MustCompile(`^ {0,3}(:{3,})\s*$`)
+)
+
+// calloutParser parses fenced callouts such as ":::note".
+// A callout is closed by a fence that has at least as many colons as the opening fence.
+type calloutParser struct{}
+
+func (p calloutParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (p calloutParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	m := calloutFenceOpenRe.FindSubmatch(line)
+	if m == nil || !isCalloutType(string(m[2])) {
+		return nil, parser.NoChildren
+	}
+	callout := newCallout(string(m[2]), string(m[3]))
+	callout.fence = string(m[1])
+	advanceLine(reader, line, segment)
+	return callout, parser.HasChildren
+}
+
+func (p calloutParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if m := calloutFenceCloseRe.FindSubmatch(line); m != nil && len(m[1]) >= len(node.(*Callout).fence) {
+		advanceLine(reader, line, segment)
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (p calloutParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p calloutParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p calloutParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+func (r customRenderer) renderCallout(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, err := w.WriteString("</div>\n")
+		return ast.WalkContinue, err
+	}
+	n := node.(*Callout)
+	_, err := fmt.Fprintf(w, `<div class="callout callout-%s"><p class="callout-title">%s</p>`+"\n", n.CalloutType, util.EscapeHTML([]byte(n.Title)))
+	return ast.WalkContinue, err
+}
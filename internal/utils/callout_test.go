@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestRenderCallout(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{
+			name: "Alert",
+			md:   "> [!WARNING]\n> Be **careful**.\n>\n> Really.",
+			want: `<div class="callout callout-warning"><p class="callout-title">Warning</p>` + "\n<p>Be <strong>careful</strong>.</p>\n<p>Really.</p>\n</div>\n",
+		},
+		{
+			name: "Alert with title",
+			md:   "> [!tip] Hint\n> text",
+			want: `<div class="callout callout-tip"><p class="callout-title">Hint</p>` + "\n<p>text</p>\n</div>\n",
+		},
+		{
+			name: "Alert without content",
+			md:   "> [!NOTE]",
+			want: `<div class="callout callout-note"><p class="callout-title">Note</p>` + "\n</div>\n",
+		},
+		{
+			name: "Unknown alert",
+			md:   "> [!UNKNOWN]\n> text",
+			want: "<blockquote>\n<p>[!UNKNOWN]\ntext</p>\n</blockquote>\n",
+		},
+		{
+			name: "Blockquote",
+			md:   "> text",
+			want: "<blockquote>\n<p>text</p>\n</blockquote>\n",
+		},
+		{
+			name: "Fenced",
+			md:   "::::important Read <this>\ntext\n:::caution\n- a\n:::\n::::\n\nafter",
+			want: `<div class="callout callout-important"><p class="callout-title">Read &lt;this&gt;</p>` + "\n<p>text</p>\n" +
+				`<div class="callout callout-caution"><p class="callout-title">Caution</p>` + "\n<ul>\n<li>a</li>\n</ul>\n</div>\n</div>\n<p>after</p>\n",
+		},
+		{
+			name: "Unknown fence",
+			md:   ":::unknown\ntext\n:::",
+			want: "<p>:::unknown\ntext\n:::</p>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// codeInfoTokenRe matches the tokens of the info string of a fenced code block,
+// such as `go`, `title="main.go"`, `{3-5}` and `linenums`.
+var codeInfoTokenRe = regexp.MustCompile(`([a-zA-Z][\w-]*)="([^"]*)"|\{([^}]*)\}|(\S+)`)
+
+// codeLineClassPattern matches the classes of a line of a code block.
+var codeLineClassPattern = regexp.MustCompile(`^line( hl)?( diff-(add|remove))?$`)
+
+// codeBlockClassPattern matches the classes of the elements around a code block.
+var codeBlockClassPattern = regexp.MustCompile(`^(code-block( copy)?|code-title)$`)
+
+// codeInfo holds the attributes in the info string of a fenced code block.
+// For example, ```go title="main.go" {3-5} linenums diff
+// The "include", "lines" and "region" attributes are handled by includeCode.
+type codeInfo struct {
+	Language    string
+	Title       string
+	LineNumbers bool
+	StartLine   int          // StartLine is the number of the first line. It is set by linenums="10".
+	Highlights  map[int]bool // Highlights holds the numbers of the highlighted lines.
+	Diff        bool         // Diff colors the lines that start with "+" or "-".
+	Attributes  map[string]string
+}
+
+// parseLineRanges parses comma-separated line numbers and ranges such as "1,3-5".
+func parseLineRanges(ranges string) (map[int]bool, error) {
+	lines := map[int]bool{}
+	for _, r := range strings.Split(ranges, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(r, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil {
+			return nil, errors.Errorf("invalid line range: %s", r)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(last))
+			if err != nil {
+				return nil, errors.Errorf("invalid line range: %s", r)
+			}
+		}
+		if start < 1 || end < start {
+			return nil, errors.Errorf("invalid line range: %s", r)
+		}
+		for i := start; i <= end; i++ {
+			lines[i] = true
+		}
+	}
+	return lines, nil
+}
+
+// parseCodeInfo parses the info string of a fenced code block.
+// The first token is the language unless it is an attribute.
+func parseCodeInfo(info string) (codeInfo, error) {
+	ci := codeInfo{StartLine: 1, Highlights: map[int]bool{}, Attributes: map[string]string{}}
+	for i, m := range codeInfoTokenRe.FindAllStringSubmatch(info, -1) {
+		switch {
+		case m[1] != "":
+			ci.Attributes[m[1]] = m[2]
+		case strings.HasPrefix(m[0], "{"):
+			lines, err := parseLineRanges(m[3])
+			if err != nil {
+				return ci, err
+			}
+			for line := range lines {
+				ci.Highlights[line] = true
+			}
+		case i == 0:
+			ci.Language = m[4]
+		case m[4] == "linenums":
+			ci.LineNumbers = true
+		case m[4] == "diff":
+			ci.Diff = true
+		}
+	}
+	ci.Title = ci.Attributes["title"]
+	if start, exists := ci.Attributes["linenums"]; exists {
+		n, err := strconv.Atoi(start)
+		if err != nil || n < 0 {
+			return ci, errors.Errorf("invalid linenums: %s", start)
+		}
+		ci.LineNumbers = true
+		ci.StartLine = n
+	} else if lines, exists := ci.Attributes["lines"]; exists {
+		// The line numbers of included lines start at the first included line.
+		if start, _, err := parseLineRange(lines); err == nil {
+			ci.StartLine = start
+		}
+	}
+	return ci, nil
+}
+
+// validateCodeInfo validates the info strings of the fenced code blocks, so that an invalid info string
+// is reported with its file and line by every command that parses the document, not only when the page is rendered.
+func (d *Document) validateCodeInfo() error {
+	return ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		block, ok := n.(*ast.FencedCodeBlock)
+		if !ok || !entering || block.Info == nil {
+			return ast.WalkContinue, nil
+		}
+		ci, err := parseCodeInfo(string(block.Info.Segment.Value(d.Source)))
+		if err == nil {
+			if lines, exists := ci.Attributes["lines"]; exists {
+				_, _, err = parseLineRange(lines)
+			}
+		}
+		if err != nil {
+			return ast.WalkStop, errors.Errorf("%s:%d: %s", d.FileName, d.lineAt(block.Info.Segment.Start), err)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// lineMode determines if the code block is rendered line by line.
+func (ci codeInfo) lineMode() bool {
+	return ci.LineNumbers || ci.Diff || len(ci.Highlights) > 0
+}
+
+// lineClass returns the classes of a line of the code block.
+func (ci codeInfo) lineClass(number int, tokens []chroma.Token) string {
+	class := "line"
+	if ci.Highlights[number] {
+		class += " hl"
+	}
+	if !ci.Diff {
+		return class
+	}
+	var text strings.Builder
+	for _, token := range tokens {
+		text.WriteString(token.Value)
+	}
+	switch {
+	case strings.HasPrefix(text.String(), "+"):
+		class += " diff-add"
+	case strings.HasPrefix(text.String(), "-"):
+		class += " diff-remove"
+	}
+	return class
+}
+
+// codeBlockText returns the content of a code block.
+func codeBlockText(node ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		buf.Write(line.Value(source))
+	}
+	return buf.String()
+}
+
+// writeLines writes each line of a code block as a span with its line number.
+func writeLines(w util.BufWriter, ci codeInfo, tokens []chroma.Token) error {
+	for i, line := range chroma.SplitTokensIntoLines(tokens) {
+		number := ci.StartLine + i
+		if _, err := fmt.Fprintf(w, `<span class="%s">`, ci.lineClass(number, line)); err != nil {
+			return err
+		}
+		if ci.LineNumbers {
+			if _, err := fmt.Fprintf(w, `<span class="ln">%d</span>`, number); err != nil {
+				return err
+			}
+		}
+		if _, err := w.WriteString(`<span class="cl">`); err != nil {
+			return err
+		}
+		if err := writeTokens(w, line); err != nil {
+			return err
+		}
+		if _, err := w.WriteString("</span></span>"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r customRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.FencedCodeBlock)
+	info := ""
+	if n.Info != nil {
+		info = string(n.Info.Segment.Value(source))
+	}
+	ci, err := parseCodeInfo(info)
+	if err != nil {
+		return 0, err
+	}
+	code := includedCode(n, source)
+	if kind, exists := diagramLanguages[ci.Language]; exists {
+		return ast.WalkSkipChildren, renderDiagram(w, n, kind, code)
+	}
+
+	wrapped := ci.Title != "" || markdownOptions.CopyButton
+	if wrapped {
+		class := "code-block"
+		if markdownOptions.CopyButton {
+			class += " copy"
+		}
+		if _, err := fmt.Fprintf(w, `<div class="%s">`, class); err != nil {
+			return 0, err
+		}
+		if ci.Title != "" {
+			if _, err := fmt.Fprintf(w, `<div class="code-title">%s</div>`, util.EscapeHTML([]byte(ci.Title))); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	startTag := "<pre><code>"
+	if ci.Language != "" {
+		startTag = fmt.Sprintf(`<pre><code class="language-%s">`, util.EscapeHTML([]byte(ci.Language)))
+	}
+	tokens := []chroma.Token{{Type: chroma.Text, Value: code}}
+	if lexer := markdownOptions.Highlight.lexer(ci.Language); lexer != nil {
+		startTag = `<pre class="chroma">` + strings.TrimPrefix(startTag, "<pre>")
+		iterator, err := lexer.Tokenise(nil, code)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		tokens = iterator.Tokens()
+	}
+	if _, err := w.WriteString(startTag); err != nil {
+		return 0, err
+	}
+
+	if ci.lineMode() {
+		err = writeLines(w, ci, tokens)
+	} else {
+		err = writeTokens(w, tokens)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	endTag := "</code></pre>\n"
+	if wrapped {
+		endTag = "</code></pre></div>\n"
+	}
+	if _, err := w.WriteString(endTag); err != nil {
+		return 0, err
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCodeInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    string
+		want    codeInfo
+		wantErr bool
+	}{
+		{
+			name: "Language",
+			info: "go",
+			want: codeInfo{Language: "go", StartLine: 1, Highlights: map[int]bool{}, Attributes: map[string]string{}},
+		},
+		{
+			name: "Attributes",
+			info: `go title="main.go" {1,3-4} linenums diff`,
+			want: codeInfo{
+				Language:    "go",
+				Title:       "main.go",
+				LineNumbers: true,
+				StartLine:   1,
+				Highlights:  map[int]bool{1: true, 3: true, 4: true},
+				Diff:        true,
+				Attributes:  map[string]string{"title": "main.go"},
+			},
+		},
+		{
+			name: "Start line",
+			info: `go linenums="10"`,
+			want: codeInfo{
+				Language:    "go",
+				LineNumbers: true,
+				StartLine:   10,
+				Highlights:  map[int]bool{},
+				Attributes:  map[string]string{"linenums": "10"},
+			},
+		},
+		{
+			name:    "Invalid range",
+			info:    "go {5-3}",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid start line",
+			info:    `go linenums="a"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCodeInfo(tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCodeInfo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCodeInfo() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderFencedCodeBlock_Lines(t *testing.T) {
+	tests := []struct {
+		name string
+		opts MarkdownOptions
+		md   string
+		want string
+	}{
+		{
+			name: "Title and line numbers",
+			md:   "```txt title=\"a<b>.txt\" linenums=\"9\" {10}\na\nb\n```",
+			want: `<div class="code-block"><div class="code-title">a&lt;b&gt;.txt</div><pre><code class="language-txt">` +
+				`<span class="line"><span class="ln">9</span><span class="cl">a` + "\n" + `</span></span>` +
+				`<span class="line hl"><span class="ln">10</span><span class="cl">b` + "\n" + `</span></span>` +
+				"</code></pre></div>\n",
+		},
+		{
+			name: "Diff",
+			md:   "```txt diff\n a\n-b\n+c\n```",
+			want: `<pre><code class="language-txt">` +
+				`<span class="line"><span class="cl"> a` + "\n" + `</span></span>` +
+				`<span class="line diff-remove"><span class="cl">-b` + "\n" + `</span></span>` +
+				`<span class="line diff-add"><span class="cl">+c` + "\n" + `</span></span>` +
+				"</code></pre>\n",
+		},
+		{
+			name: "Copy button",
+			opts: MarkdownOptions{CopyButton: true},
+			md:   "```\na\n```",
+			want: "<div class=\"code-block copy\"><pre><code>a\n</code></pre></div>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureMarkdown(tt.opts)
+			defer ConfigureMarkdown(MarkdownOptions{})
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDocument_InvalidCodeInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		md      string
+		wantErr string
+	}{
+		{
+			name:    "Highlight",
+			md:      "{}\n---\n# T\n\n```go {3-x}\nfmt.Println()\n```",
+			wantErr: "a.md:5: invalid line range: 3-x",
+		},
+		{
+			name:    "Line numbers",
+			md:      "{}\n---\n# T\n\n```go\n```\n\n```go linenums=\"x\"\n```",
+			wantErr: "a.md:8: invalid linenums: x",
+		},
+		{
+			name:    "Included lines",
+			md:      "{}\n---\n# T\n\n```go include=\"main.go\" lines=\"5-1\"\n```",
+			wantErr: "a.md:5: invalid lines: 5-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseDocument("a.md", []byte(tt.md)); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseDocument() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -11,14 +11,29 @@ var (
 	hashRe        = regexp.MustCompile(`(\s|\?|:|&|=|%|"|'|\/|@|\\)`)
 	lessThanRe    = regexp.MustCompile("<")
 	greaterThanRe = regexp.MustCompile(">")
+	nonSlugRe     = regexp.MustCompile(`[^a-z0-9]+`)
 )
 
 const (
-	BODY          = "__BODY__"
-	HEADER        = "__HEADER__"
-	INDEX         = "__INDEX__"
-	CSS           = "__CSS__"
-	URL           = "__URL__"
-	IMAGE_DIR     = "images"
-	CSS_FILE_NAME = "app.css"
+	BODY             = "__BODY__"
+	HEADER           = "__HEADER__"
+	INDEX            = "__INDEX__"
+	CSS              = "__CSS__"
+	SCRIPTS          = "__SCRIPTS__"
+	URL              = "__URL__"
+	LANG             = "__LANG__"
+	ALTERNATES       = "__ALTERNATES__"
+	SWITCHER         = "__LANGUAGE_SWITCHER__"
+	VERSION          = "__VERSION__"
+	VERSION_SWITCHER = "__VERSION_SWITCHER__"
+	VERSION_BANNER   = "__VERSION_BANNER__"
+	CREATED          = "__CREATED__"
+	UPDATED          = "__UPDATED__"
+	AUTHORS          = "__AUTHORS__"
+	EDIT_LINK        = "__EDIT_LINK__"
+	SOURCE_LINK      = "__SOURCE_LINK__"
+	HISTORY_LINK     = "__HISTORY_LINK__"
+	IMAGE_DIR        = "images"
+	CSS_FILE_NAME    = "app.css"
+	JS_FILE_NAME     = "app.js"
 )
@@ -2,24 +2,31 @@ package utils
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/pkg/errors"
 	"github.com/yuin/goldmark"
 	"golang.org/x/net/html"
-	"golang.org/x/sync/errgroup"
 )
 
 type PageMeta struct {
-	Category string `json:"category,omitempty"`
-	Order    int    `json:"order,omitempty"`
-	Date     string `json:"date,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Order       int    `json:"order,omitempty"`
+	Date        string `json:"date,omitempty"`
+	TOCMinLevel int    `json:"tocMinLevel,omitempty"`
+	TOCMaxLevel int    `json:"tocMaxLevel,omitempty"`
+	WritingMode string `json:"writing-mode,omitempty"` // WritingMode is "vertical" or "horizontal".
+	Description string `json:"description,omitempty"`  // Description overrides the description generated from the text.
+	// TranslationKey pairs the page with its translations whose paths are different.
+	TranslationKey string `json:"translationKey,omitempty"`
+	// SourceHash is the hash of the page in the default language that the translation is based on.
+	SourceHash string `json:"sourceHash,omitempty"`
 }
 
 type Category struct {
@@ -34,9 +41,12 @@ type Meta struct {
 }
 
 type Page struct {
-	Meta  Meta   `json:"meta,omitempty"`
-	Title string `json:"title,omitempty"`
-	URL   string `json:"url,omitempty"`
+	Meta        Meta   `json:"meta,omitempty"`
+	Title       string `json:"title,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"` // Description is HTML-escaped.
+	// Updated is the date of the last commit of the page, or the date in the metadata.
+	Updated time.Time `json:"updated,omitempty"`
 }
 
 type IndexItem struct {
@@ -45,8 +55,9 @@ type IndexItem struct {
 }
 
 type IndexItemPage struct {
-	Title string `json:"title,omitempty"`
-	URL   string `json:"url,omitempty"`
+	Title       string `json:"title,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"` // Description is HTML-escaped.
 }
 
 type IndexItemPagesMap map[int]IndexItemPage
@@ -54,7 +65,7 @@ type IndexItemPagesMap map[int]IndexItemPage
 var markdown goldmark.Markdown
 
 func init() {
-	markdown = NewMarkdown()
+	markdown = NewMarkdown(markdownOptions)
 }
 
 // CreateHash generates a hash value from the given text.
@@ -66,23 +77,24 @@ func CreateHash(text string) string {
 	return text
 }
 
-// GetMetaAndMd extracts metadata and markdown text from the content of a markdown file.
-// The content is split by "---", where the first part is interpreted as JSON format metadata, and the second part as markdown text.
-func GetMetaAndMd(content string, categoryOrders map[string]int) (*Meta, string, error) {
-	parts := SEPARATOR.Split(content, 2)
-	if len(parts) != 2 {
-		return nil, "", errors.WithStack(errors.New("invalid content format"))
-	}
-
-	pm := PageMeta{}
-	err := json.Unmarshal([]byte(parts[0]), &pm)
-	if err != nil {
-		return nil, "", errors.WithStack(err)
+// CreateSlug generates an ASCII slug from the given text.
+// It lowercases the text and replaces each run of characters other than ASCII letters and digits with "-".
+// If no ASCII letters or digits remain, it returns "section".
+func CreateSlug(text string) string {
+	slug := nonSlugRe.ReplaceAllString(strings.ToLower(text), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		return "section"
 	}
+	return slug
+}
 
+// CreateMeta creates the metadata of a page from the metadata written in a markdown file.
+// It returns an error if the category does not exist in categoryOrders.
+func CreateMeta(pm PageMeta, categoryOrders map[string]int) (*Meta, error) {
 	categoryOrder, exist := categoryOrders[pm.Category]
 	if !exist {
-		return nil, "", errors.WithStack(errors.Errorf("%s does not exist in CATGEGORIES", pm.Category))
+		return nil, errors.WithStack(errors.Errorf("%s does not exist in CATGEGORIES", pm.Category))
 	}
 
 	meta := &Meta{
@@ -93,28 +105,13 @@ func GetMetaAndMd(content string, categoryOrders map[string]int) (*Meta, string,
 		Order: pm.Order,
 		Date:  pm.Date,
 	}
-	md := strings.TrimSpace(parts[1])
-	return meta, md, nil
-}
-
-func GetMd(content string) (string, error) {
-	parts := SEPARATOR.Split(content, 2)
-	if len(parts) != 2 {
-		return "", errors.WithStack(errors.New("invalid content format"))
-	}
-	md := strings.TrimSpace(parts[1])
-	return md, nil
+	return meta, nil
 }
 
 // CreateTitle generates a title from the markdown text.
 // It uses the first heading (# Title) as the title.
 func CreateTitle(md string) string {
-	start := len("# ")
-	end := strings.Index(md, "\n")
-	if end == -1 {
-		return md[start:]
-	}
-	return md[start:end]
+	return ParseMarkdown(md).Title
 }
 
 // CreateURL generates a URL from the specified directory and file name.
@@ -136,97 +133,103 @@ func GetDirAndName(path string) (string, string) {
 	return dir, name
 }
 
-// CreatePageData generates page data from a markdown file name.
-// It parses the file content to extract metadata, title, and URL, and returns a Page struct containing these.
-func CreatePageData(markDownFileName, sourceDir, baseURL string, categoryOrders map[string]int) (*Page, error) {
-	content, err := os.ReadFile(markDownFileName)
+// CreatePageData generates page data from a parsed markdown file.
+// It creates the metadata and URL of the document, and returns a Page struct containing these, the title and the description.
+func CreatePageData(doc *Document, sourceDir, baseURL string, categoryOrders map[string]int) (*Page, error) {
+	meta, err := CreateMeta(doc.PageMeta, categoryOrders)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, errors.WithMessage(err, doc.FileName)
 	}
 
-	meta, md, err := GetMetaAndMd(string(content), categoryOrders)
-	if err != nil {
-		return nil, err
-	}
-
-	title := CreateTitle(md)
-	dir, name := GetDirAndName(markDownFileName)
-	url := CreateURL(dir, name, sourceDir, baseURL)
-
 	page := &Page{
-		Meta:  *meta,
-		Title: title,
-		URL:   url,
+		Meta:        *meta,
+		Title:       doc.Title,
+		URL:         doc.URL(sourceDir, baseURL),
+		Description: doc.Description(),
+		Updated:     doc.Updated(),
+	}
+	// 日付がなければ最初のコミットの日付を使う
+	if page.Meta.Date == "" && doc.History != nil {
+		page.Meta.Date = doc.History.Created.UTC().Format(DateTime)
 	}
 
 	return page, nil
 }
 
-// extractTextNodes extracts text nodes from an HTML string.
-func extractTextNodes(htmlStr string) (string, error) {
-	doc, err := html.Parse(strings.NewReader(htmlStr))
-	if err != nil {
-		return "", errors.WithStack(err)
-	}
-
-	var buf bytes.Buffer
-	var stack []*html.Node
-	stack = append(stack, doc)
-
-	for len(stack) > 0 {
-		n := stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
-
-		if n.Type == html.TextNode {
-			buf.WriteString(n.Data)
-		}
-
-		// 子ノードを逆順でスタックに追加
-		for c := n.LastChild; c != nil; c = c.PrevSibling {
-			stack = append(stack, c)
-		}
+// createDescriptionFromText joins the lines of the plain text, truncates it to length characters and escapes it.
+// The text is truncated by characters before it is escaped, so that neither multibyte characters nor character references are split.
+func createDescriptionFromText(text string, length int) string {
+	result := joinLines(text)
+	if runes := []rune(result); len(runes) > length {
+		result = string(runes[:length])
 	}
-
-	return buf.String(), nil
+	return html.EscapeString(result)
 }
 
-// CreateDescription generates a description from an HTML string.
-// It extracts text nodes and removes newlines and specific characters, then uses the first 300 characters to generate the description.
-func CreateDescription(htmlStr string) (string, error) {
-	text, err := extractTextNodes(htmlStr)
-	if err != nil {
-		return "", err
-	}
-
-	start := strings.Index(text, "\n")
-	if start != -1 {
-		text = text[start:]
-	}
-
-	result := strings.ReplaceAll(text, "\n", "")
-	result = html.EscapeString(result)
-	if len(result) > 300 {
-		result = result[:300]
-	}
+// newPolicy creates the policy that sanitizes the HTML generated from markdown.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(anchor|Link|current|footnote-ref|footnote-backref)$`)).OnElements("a")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|backlink)$`)).OnElements("a")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-endnotes$`)).OnElements("div")
+	p.AllowAttrs("aria-current").Matching(regexp.MustCompile(`^page$`)).OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(index-menu|header-list( scroll-spy)?|toc)$`)).OnElements("nav")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(h1|h2|h3|h4|h5|h6|callout-title)$`)).OnElements("p")
+	p.AllowAttrs("loading").Matching(regexp.MustCompile(`^lazy$`)).OnElements("img")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^chroma$|` + diagramClassPattern.String())).OnElements("pre")
+	p.AllowAttrs("class").Matching(codeLanguagePattern).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(highlightClassPattern.String() + "|" + codeLineClassPattern.String() + "|^tcy$")).OnElements("span")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(strings.Join([]string{
+		codeBlockClassPattern.String(), shortcodeClassPattern.String(), calloutClassPattern.String(), `^footnotes$`, diagramClassPattern.String(), `^vertical$`,
+	}, "|"))).OnElements("div")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^tab$`)).OnElements("details")
+	p.AllowAttrs("src").Matching(youtubePattern).OnElements("iframe")
+	p.AllowAttrs("title").OnElements("iframe")
+	p.AllowAttrs("allowfullscreen").Matching(regexp.MustCompile(`^$`)).OnElements("iframe")
+	p.AllowAttrs("src", "controls", "preload").OnElements("audio", "video")
+	p.AllowAttrs("poster").OnElements("video")
+	p.AllowNoAttrs().OnElements("rb")
+	p.AllowNoAttrs().OnElements(mathMLElements...)
+	p.AllowAttrs("display").Matching(regexp.MustCompile(`^block$`)).OnElements("math")
+	p.AllowAttrs("encoding").Matching(regexp.MustCompile(`^application/x-tex$`)).OnElements("annotation")
+	p.AllowAttrs("mathvariant").Matching(regexp.MustCompile(`^normal$`)).OnElements("mi")
+	p.AllowAttrs("largeop", "fence", "stretchy").Matching(regexp.MustCompile(`^true$`)).OnElements("mo")
+	p.AllowAttrs("accent").Matching(regexp.MustCompile(`^true$`)).OnElements("mover")
+	p.AllowAttrs("accentunder").Matching(regexp.MustCompile(`^true$`)).OnElements("munder")
+	p.AllowAttrs("linethickness").Matching(regexp.MustCompile(`^0$`)).OnElements("mfrac")
+	p.AllowAttrs("width").Matching(regexp.MustCompile(`^-?[0-9.]+em$`)).OnElements("mspace")
+	p.AllowAttrs("columnalign").Matching(regexp.MustCompile(`^(left|right)( (left|right))*$`)).OnElements("mtable")
+	return p
+}
 
-	return result, nil
+// newUnsafePolicy creates the policy that sanitizes the body when raw HTML is enabled.
+// It additionally allows the classes, styles and data attributes that raw HTML is usually written with.
+func newUnsafePolicy() *bluemonday.Policy {
+	p := newPolicy()
+	p.AllowStyling()
+	p.AllowDataAttributes()
+	p.AllowAttrs("style").Globally()
+	return p
 }
 
 // CreateHTML generates an HTML page using the given parameters.
 // It applies the parameters to the layout template and returns the completed HTML string.
-func CreateHTML(layout, title, body, description, url, cssPath, indexMenu, headerList string) string {
-	p := bluemonday.UGCPolicy()
-	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(anchor|Link)$`)).OnElements("a")
-	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(index-menu|header-list)$`)).OnElements("nav")
-	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(h1|h2|h3|h4)$`)).OnElements("p")
-	p.AllowAttrs("loading").Matching(regexp.MustCompile(`^lazy$`)).OnElements("img")
+// The scripts are trusted HTML and are not sanitized.
+// If raw HTML is enabled by MarkdownOptions.Unsafe, the body is sanitized by a relaxed policy.
+func CreateHTML(layout, title, body, description, url, cssPath, scripts, indexMenu, headerList string) string {
+	p := newPolicy()
+	bodyPolicy := p
+	if markdownOptions.Unsafe {
+		bodyPolicy = newUnsafePolicy()
+	}
 	html := TITLE.ReplaceAllString(layout, p.Sanitize(title))
-	html = DESCRIPTION.ReplaceAllString(html, p.Sanitize(description))
+	html = DESCRIPTION.ReplaceAllLiteralString(html, p.Sanitize(description))
 	html = strings.Replace(html, URL, p.Sanitize(url), 1)
 	html = strings.Replace(html, CSS, p.Sanitize(cssPath), 1)
+	html = strings.Replace(html, SCRIPTS, scripts, 1)
 	html = strings.Replace(html, INDEX, p.Sanitize(indexMenu), 1)
 	html = strings.Replace(html, HEADER, p.Sanitize(headerList), 1)
-	html = strings.Replace(html, BODY, p.Sanitize(body), 1)
+	html = strings.Replace(html, BODY, bodyPolicy.Sanitize(body), 1)
 	return html
 }
 
@@ -261,8 +264,9 @@ func CreateIndexItems(pages []*Page) ([]IndexItem, error) {
 		}
 
 		indexItemsMap[categoryOrder].Pages[pageOrder] = IndexItemPage{
-			Title: page.Title,
-			URL:   page.URL,
+			Title:       page.Title,
+			URL:         page.URL,
+			Description: page.Description,
 		}
 	}
 
@@ -293,95 +297,110 @@ func CreateIndexItems(pages []*Page) ([]IndexItem, error) {
 }
 
 // CreateIndexMenu generates an HTML navigation menu from a slice of IndexItem.
+// Every category is opened and no page is marked as the current page.
 func CreateIndexMenu(items []IndexItem) string {
-	var menu strings.Builder
-	menu.WriteString("<nav class=\"index-menu\">")
+	return NewIndexMenu(items, true).Render("")
+}
+
+// IndexMenu renders the navigation menu for each page.
+// The HTML of every category is rendered once in NewIndexMenu, and only the category
+// that contains the current page is rendered again in Render.
+type IndexMenu struct {
+	items      []IndexItem
+	categories []string       // categories holds the rendered HTML of each item.
+	positions  map[string]int // positions maps the URL of a page to the index of its item.
+}
 
-	for _, item := range items {
-		menu.WriteString(fmt.Sprintf("\n<details open>\n<summary>%s</summary>", html.EscapeString(item.Name)))
+// NewIndexMenu creates an IndexMenu from a slice of IndexItem.
+// If openAll is true, every category is opened. Otherwise only the category of the current page is opened.
+func NewIndexMenu(items []IndexItem, openAll bool) *IndexMenu {
+	m := &IndexMenu{
+		items:      items,
+		categories: make([]string, len(items)),
+		positions:  map[string]int{},
+	}
+	for i, item := range items {
+		m.categories[i] = renderIndexMenuCategory(item, openAll, "")
 		for _, page := range item.Pages {
-			menu.WriteString(fmt.Sprintf("\n<p><a href=\"%s\">%s</a></p>", html.EscapeString(page.URL), html.EscapeString(page.Title)))
+			m.positions[page.URL] = i
 		}
-		menu.WriteString("\n</details>")
 	}
+	return m
+}
 
+// Render generates the HTML navigation menu for the page at currentURL.
+// The link to the current page has aria-current="page" and the "current" class,
+// and the category of the current page is opened.
+func (m *IndexMenu) Render(currentURL string) string {
+	current, exists := m.positions[currentURL]
+	var menu strings.Builder
+	menu.WriteString("<nav class=\"index-menu\">")
+	for i, category := range m.categories {
+		if exists && i == current {
+			menu.WriteString(renderIndexMenuCategory(m.items[i], true, currentURL))
+		} else {
+			menu.WriteString(category)
+		}
+	}
 	menu.WriteString("\n</nav>")
 	return menu.String()
 }
 
-// CreatePage generates the HTML for an individual page using the given parameters.
-func CreatePage(layout, md, title, url, cssPath, indexMenu, headerList string) (string, error) {
-	var buf bytes.Buffer
-	if err := markdown.Convert([]byte(md), &buf); err != nil {
-		return "", errors.WithStack(err)
-	}
-	body := buf.String()
-
-	description, err := CreateDescription(body)
-	if err != nil {
-		return "", err
+// renderIndexMenuCategory generates the HTML of a category in the navigation menu.
+func renderIndexMenuCategory(item IndexItem, open bool, currentURL string) string {
+	var category strings.Builder
+	if open {
+		category.WriteString("\n<details open>")
+	} else {
+		category.WriteString("\n<details>")
 	}
-
-	html := CreateHTML(layout, title, body, description, url, cssPath, indexMenu, headerList)
-	return html, nil
-}
-
-// IsHeader determines if a given text line is a markdown header.
-func IsHeader(line string) bool {
-	for i := 2; i <= 5; i++ {
-		if strings.HasPrefix(line, strings.Repeat("#", i-1)+" ") {
-			return true
+	category.WriteString(fmt.Sprintf("\n<summary>%s</summary>", html.EscapeString(item.Name)))
+	for _, page := range item.Pages {
+		if page.URL == currentURL {
+			category.WriteString(fmt.Sprintf("\n<p><a href=\"%s\" class=\"current\" aria-current=\"page\">%s</a></p>",
+				html.EscapeString(page.URL), html.EscapeString(page.Title)))
+		} else {
+			category.WriteString(fmt.Sprintf("\n<p><a href=\"%s\">%s</a></p>", html.EscapeString(page.URL), html.EscapeString(page.Title)))
 		}
 	}
-	return false
+	category.WriteString("\n</details>")
+	return category.String()
 }
 
-// IsHeader determines if a given text line is a markdown code.
-func IsCode(line string) bool {
-	return strings.HasPrefix(line, "```")
+// trustedPlaceholderRe matches the placeholders returned by trustedPlaceholder.
+var trustedPlaceholderRe = regexp.MustCompile(`__[A-Z]+_[0-9a-f]{32}__`)
+
+// trustedPlaceholder returns the text that is replaced with trusted HTML such as a pre-rendered diagram after the page is sanitized.
+func trustedPlaceholder(kind, html string) string {
+	hash := uuid.NewSHA1(uuid.Nil, []byte(html)).String()
+	return fmt.Sprintf("__%s_%s__", kind, strings.ReplaceAll(hash, "-", ""))
 }
 
-// CreateHeaderList generates HTML for a header list from markdown text.
-func CreateHeaderList(md string) (string, error) {
-	lines := strings.Split(md, "\n")
-	isInCode := false
-	filtered, err := Filter(lines, func(line string, _ int) (bool, error) {
-		if IsCode(line) {
-			isInCode = !isInCode
-		}
-		// ignore `#` in code tag
-		if isInCode {
-			return false, nil
-		}
-		return IsHeader(line), nil
-	})
+// CreatePage generates the HTML for an individual page using the given parameters.
+// The body, description and header list are generated from the AST of the document.
+func CreatePage(layout string, doc *Document, url, cssPath, scripts, indexMenu string, tocOpts TOCOptions) (string, error) {
+	tocOpts, err := tocOpts.ForPage(doc.PageMeta)
+	if err != nil {
+		return "", errors.WithMessage(err, doc.FileName)
+	}
+	doc.SetTOC(CreateTOC(doc.Headings, tocOpts, "toc"))
+	body, err := doc.HTML()
 	if err != nil {
 		return "", err
 	}
-	headers := []string{}
-	for _, line := range filtered {
-		for i := 2; i <= 5; i++ {
-			if strings.HasPrefix(line, strings.Repeat("#", i-1)+" ") {
-				headerContent := strings.TrimSpace(line[i:])
-				href := CreateHash(headerContent)
-				var html bytes.Buffer
-				if err := markdown.Convert([]byte(headerContent), &html); err != nil {
-					return "", errors.WithStack(err)
-				}
-				text, err := extractTextNodes(html.String())
-				if err != nil {
-					return "", err
-				}
-				headers = append(headers, fmt.Sprintf(`<p class="h%d"><a href="#%s">%s</a></p>`, i-1, href, text))
-			}
-		}
+	description := doc.Description()
+	headerList := CreateHeaderList(doc.Headings, tocOpts)
+	if doc.Vertical() {
+		body = verticalBody(body)
 	}
-	result := fmt.Sprintf(`<nav class="header-list">%s</nav>`, strings.Join(headers, "\n"))
-	return result, nil
+
+	html := CreateHTML(layout, doc.Title, body, description, url, cssPath, scripts, indexMenu, headerList)
+	return doc.insertShortcodes(doc.insertDiagrams(html)), nil
 }
 
 // CreateIndexPage generates the HTML for an index page from index items.
-func CreateIndexPage(layout, baseURL, header, title, description, cssPath string, indexItems []IndexItem) (string, error) {
+// If descriptions is true, the description of each page is shown under its link.
+func CreateIndexPage(layout, baseURL, header, title, description, cssPath, scripts string, indexItems []IndexItem, descriptions bool) (string, error) {
 	var builder strings.Builder
 
 	// ヘッダーを追加
@@ -392,6 +411,9 @@ func CreateIndexPage(layout, baseURL, header, title, description, cssPath string
 		builder.WriteString("\n## " + item.Name + "\n")
 		for _, page := range item.Pages {
 			builder.WriteString(fmt.Sprintf("* [%s](%s)\n", page.Title, page.URL))
+			if descriptions && page.Description != "" {
+				builder.WriteString("\n  " + escapeMarkdown(html.UnescapeString(page.Description)) + "\n\n")
+			}
 		}
 	}
 
@@ -399,19 +421,7 @@ func CreateIndexPage(layout, baseURL, header, title, description, cssPath string
 	if err := markdown.Convert([]byte(builder.String()), &body); err != nil {
 		return "", errors.WithStack(err)
 	}
-	return CreateHTML(layout, title, body.String(), description, baseURL, cssPath, "", ""), nil
-}
-
-// createPageTask returns a task that generates page data from a specified markdown file.
-func createPageTask(markDownFileName string, pages []*Page, sourceDir, baseURL string, categoryOrders map[string]int, index int) func() error {
-	return func() error {
-		page, err := CreatePageData(markDownFileName, sourceDir, baseURL, categoryOrders)
-		if err != nil {
-			return err
-		}
-		pages[index] = page
-		return nil
-	}
+	return CreateHTML(layout, title, body.String(), description, baseURL, cssPath, scripts, "", ""), nil
 }
 
 // CreateCategoryOrders takes a string of categories separated by commas, trims any trailing comma,
@@ -442,19 +452,17 @@ func CreateCategoryOrders(categories string) map[string]int {
 	return co
 }
 
-// CreatePages asynchronously generates a slice of Page data from multiple markdown files.
-func CreatePages(markDownFileNames []string, sourceDir, baseURL, categories string) ([]*Page, error) {
-	var g errgroup.Group
-	pages := make([]*Page, len(markDownFileNames))
+// CreatePages generates a slice of Page data from parsed markdown files.
+func CreatePages(docs []*Document, sourceDir, baseURL, categories string) ([]*Page, error) {
+	pages := make([]*Page, len(docs))
 	categoryOrders := CreateCategoryOrders(categories)
 
-	for i, fileName := range markDownFileNames {
-		task := createPageTask(fileName, pages, sourceDir, baseURL, categoryOrders, i)
-		g.Go(task)
-	}
-
-	if err := g.Wait(); err != nil {
-		return nil, err
+	for i, doc := range docs {
+		page, err := CreatePageData(doc, sourceDir, baseURL, categoryOrders)
+		if err != nil {
+			return nil, err
+		}
+		pages[i] = page
 	}
 
 	return pages, nil
@@ -4,7 +4,6 @@ import (
 	"log"
 	"path/filepath"
 	"reflect"
-	"strings"
 	"testing"
 
 	"github.com/joho/godotenv"
@@ -43,6 +42,25 @@ func TestCreateHash(t *testing.T) {
 	}
 }
 
+func TestCreateSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "ASCII", text: "Hello, World!", want: "hello-world"},
+		{name: "Mixed", text: "Go言語 の Example 2", want: "go-example-2"},
+		{name: "Non-ASCII only", text: "はじめに", want: "section"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreateSlug(tt.text); got != tt.want {
+				t.Errorf("CreateSlug() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCreateTitle(t *testing.T) {
 	type args struct {
 		md string
@@ -104,71 +122,6 @@ func TestCreateURL(t *testing.T) {
 	}
 }
 
-func TestCreateDescription(t *testing.T) {
-	type args struct {
-		htmlStr string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    string
-		wantErr bool
-	}{
-		{
-			name: "Simple text",
-			args: args{htmlStr: "1\n<p>Hello World</p>"},
-			want: "Hello World",
-		},
-		{
-			name: "Text with line breaks",
-			args: args{htmlStr: "1\n<p>Hello\nWorld</p>"},
-			want: "HelloWorld",
-		},
-		{
-			name: "Text with double quotes",
-			args: args{htmlStr: "1\n<p>Hello \"World\"</p>"},
-			want: `Hello &#34;World&#34;`,
-		},
-		{
-			name: "Complex HTML",
-			args: args{htmlStr: "1\n<div><p>Hello</p><p>World</p></div>"},
-			want: "HelloWorld",
-		},
-		{
-			name: "Empty string",
-			args: args{htmlStr: ""},
-			want: "",
-		},
-		{
-			name: "Text exceeding 300 characters",
-			args: args{htmlStr: "1\n<p>" + strings.Repeat("a", 500) + "</p>"},
-			want: strings.Repeat("a", 300),
-		},
-		{
-			name: "Invalid HTML",
-			args: args{htmlStr: "1\n<p>Hello World"},
-			want: "Hello World",
-		},
-		{
-			name: "1 line",
-			args: args{htmlStr: "foo"},
-			want: "foo",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := CreateDescription(tt.args.htmlStr)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("CreateDescription() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("CreateDescription() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestCreateIndexMenu(t *testing.T) {
 	type args struct {
 		items []IndexItem
@@ -210,6 +163,58 @@ func TestCreateIndexMenu(t *testing.T) {
 	}
 }
 
+func TestIndexMenu_Render(t *testing.T) {
+	items := []IndexItem{
+		{Name: "Item1", Pages: []IndexItemPage{
+			{Title: "Page1", URL: "https://example.com/page1"},
+		}},
+		{Name: "Item2", Pages: []IndexItemPage{
+			{Title: "Page2", URL: "https://example.com/page2"},
+			{Title: "Page3", URL: "https://example.com/page3"},
+		}},
+	}
+	type args struct {
+		openAll    bool
+		currentURL string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "Open current category",
+			args: args{openAll: false, currentURL: "https://example.com/page3"},
+			want: "<nav class=\"index-menu\">\n<details>\n<summary>Item1</summary>\n<p><a href=\"https://example.com/page1\">Page1</a></p>\n</details>" +
+				"\n<details open>\n<summary>Item2</summary>\n<p><a href=\"https://example.com/page2\">Page2</a></p>" +
+				"\n<p><a href=\"https://example.com/page3\" class=\"current\" aria-current=\"page\">Page3</a></p>\n</details>\n</nav>",
+		},
+		{
+			name: "Open all categories",
+			args: args{openAll: true, currentURL: "https://example.com/page1"},
+			want: "<nav class=\"index-menu\">\n<details open>\n<summary>Item1</summary>" +
+				"\n<p><a href=\"https://example.com/page1\" class=\"current\" aria-current=\"page\">Page1</a></p>\n</details>" +
+				"\n<details open>\n<summary>Item2</summary>\n<p><a href=\"https://example.com/page2\">Page2</a></p>" +
+				"\n<p><a href=\"https://example.com/page3\">Page3</a></p>\n</details>\n</nav>",
+		},
+		{
+			name: "Unknown page",
+			args: args{openAll: false, currentURL: "https://example.com/page4"},
+			want: "<nav class=\"index-menu\">\n<details>\n<summary>Item1</summary>\n<p><a href=\"https://example.com/page1\">Page1</a></p>\n</details>" +
+				"\n<details>\n<summary>Item2</summary>\n<p><a href=\"https://example.com/page2\">Page2</a></p>" +
+				"\n<p><a href=\"https://example.com/page3\">Page3</a></p>\n</details>\n</nav>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			menu := NewIndexMenu(items, tt.args.openAll)
+			if got := menu.Render(tt.args.currentURL); got != tt.want {
+				t.Errorf("IndexMenu.Render() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCreateHTMLFileDir(t *testing.T) {
 	type args struct {
 		dir       string
@@ -258,120 +263,6 @@ func TestCreateHTMLFileDir(t *testing.T) {
 	}
 }
 
-func TestIsHeader(t *testing.T) {
-	type args struct {
-		line string
-	}
-	tests := []struct {
-		name string
-		args args
-		want bool
-	}{
-		{
-			name: "# is a header",
-			args: args{
-				line: "# This a header",
-			},
-			want: true,
-		},
-		{
-			name: "## is a header",
-			args: args{
-				line: "## This is a header",
-			},
-			want: true,
-		},
-		{
-			name: "### is a header",
-			args: args{
-				line: "### This is also a header",
-			},
-			want: true,
-		},
-		{
-			name: "#### is a header",
-			args: args{
-				line: "#### This is still a header",
-			},
-			want: true,
-		},
-		{
-			name: "##### is a header but not detected",
-			args: args{
-				line: "##### This is not detected as a header by IsHeader",
-			},
-			want: false,
-		},
-		{
-			name: "Non-header text",
-			args: args{
-				line: "This is just some text.",
-			},
-			want: false,
-		},
-		{
-			name: "Empty string",
-			args: args{
-				line: "",
-			},
-			want: false,
-		},
-		{
-			name: "There is no space",
-			args: args{
-				line: "#This a header",
-			},
-			want: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := IsHeader(tt.args.line); got != tt.want {
-				t.Errorf("IsHeader() = %v, want %v for line %v", got, tt.want, tt.args.line)
-			}
-		})
-	}
-}
-
-func TestIsCode(t *testing.T) {
-	type args struct {
-		line string
-	}
-	tests := []struct {
-		name string
-		args args
-		want bool
-	}{
-		{
-			name: "only code",
-			args: args{line: "```"},
-			want: true,
-		},
-		{
-			name: "with language",
-			args: args{line: "```go"},
-			want: true,
-		},
-		{
-			name: "start space",
-			args: args{line: " ```"},
-			want: false,
-		},
-		{
-			name: "others",
-			args: args{line: "foobar"},
-			want: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := IsCode(tt.args.line); got != tt.want {
-				t.Errorf("IsCode() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestCreateCategoryOrders(t *testing.T) {
 	type args struct {
 		categories string
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// escapeMakePath escapes the spaces and the characters that have special meanings in a Makefile.
+func escapeMakePath(path string) string {
+	path = strings.ReplaceAll(path, "$", "$$")
+	path = strings.ReplaceAll(path, "#", `\#`)
+	return strings.ReplaceAll(path, " ", `\ `)
+}
+
+// CreateDependencyRules generates the rules in the Makefile format that list the inputs of each page,
+// such as "docs/go/a.html: src/go/a.md examples/main.go".
+// The inputs are the markdown file, the partials and the included code, so make and file watchers
+// can find the pages that must be rebuilt when one of the files is changed.
+func CreateDependencyRules(docs []*Document, sourceDir, outputDir string) string {
+	var sb strings.Builder
+	for _, doc := range docs {
+		sb.WriteString(escapeMakePath(doc.HTMLFileName(sourceDir, outputDir)) + ":")
+		inputs := []string{}
+		for _, input := range doc.Inputs {
+			if !slices.Contains(inputs, input) {
+				inputs = append(inputs, input)
+				sb.WriteString(" " + escapeMakePath(input))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// CreateDependencyFileTask returns a task that writes the dependency rules to fileName.
+func CreateDependencyFileTask(rules, fileName string) func() error {
+	return func() error {
+		return errors.WithStack(os.WriteFile(fileName, []byte(rules), 0644))
+	}
+}
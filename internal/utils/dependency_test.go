@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateDependencyRules(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	files := map[string]string{
+		"src/a.md":           "{}\n---\n# A\n\n{{< include \"_partials/p.md\" >}}\n\n{{< include \"_partials/p.md\" >}}\n\n```go include=\"../examples/main.go\"\n```",
+		"src/_partials/p.md": "partial",
+		"examples/main.go":   "package main\n",
+		"src/my docs/b.md":   "{}\n---\n# B",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs, err := LoadDocuments([]string{filepath.Join(sourceDir, "a.md"), filepath.Join(sourceDir, "my docs", "b.md")}, sourceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := CreateDependencyRules(docs, sourceDir, "out")
+	want := "out/a.html: " + filepath.Join(sourceDir, "a.md") + " " + filepath.Join(sourceDir, "_partials", "p.md") + " " + filepath.Join(dir, "examples", "main.go") + "\n" +
+		`out/my\ docs/b.html: ` + escapeMakePath(filepath.Join(sourceDir, "my docs", "b.md")) + "\n"
+	if got != want {
+		t.Errorf("CreateDependencyRules() = %v, want %v", got, want)
+	}
+}
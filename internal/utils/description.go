@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+	"golang.org/x/net/html"
+)
+
+// DEFAULT_DESCRIPTION_LENGTH is the default maximum number of characters in a description.
+const DEFAULT_DESCRIPTION_LENGTH = 300
+
+// moreMarkerRe matches the <!--more--> marker that ends the excerpt of a page.
+var moreMarkerRe = regexp.MustCompile(`^\s*<!--\s*more\s*-->\s*$`)
+
+// isMoreMarker determines if a node is an HTML block of the <!--more--> marker.
+func isMoreMarker(node ast.Node, source []byte) bool {
+	if node.Kind() != ast.KindHTMLBlock {
+		return false
+	}
+	var buf bytes.Buffer
+	for i := 0; i < node.Lines().Len(); i++ {
+		line := node.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	return moreMarkerRe.Match(buf.Bytes())
+}
+
+// descriptionLength returns the maximum number of characters in a description.
+func descriptionLength() int {
+	if markdownOptions.DescriptionLength > 0 {
+		return markdownOptions.DescriptionLength
+	}
+	return DEFAULT_DESCRIPTION_LENGTH
+}
+
+// isCJK determines if a character is written without spaces between words such as kanji, kana, hangul and full-width punctuation.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		'\u3000' <= r && r <= '\u303f' || '\uff00' <= r && r <= '\uffef'
+}
+
+// isASCIISpace determines if a character is an ASCII whitespace character.
+func isASCIISpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// joinLines joins the lines of plain text with single spaces, and collapses the runs of ASCII whitespace into single spaces.
+// A newline between CJK characters is removed, because the words of CJK text are not separated by spaces.
+func joinLines(text string) string {
+	runes := []rune(strings.TrimFunc(text, isASCIISpace))
+	var buf strings.Builder
+	for i := 0; i < len(runes); i++ {
+		if !isASCIISpace(runes[i]) {
+			buf.WriteRune(runes[i])
+			continue
+		}
+		j := i
+		newline := false
+		for ; isASCIISpace(runes[j]); j++ {
+			newline = newline || runes[j] == '\n'
+		}
+		if !newline || !isCJK(runes[i-1]) || !isCJK(runes[j]) {
+			buf.WriteByte(' ')
+		}
+		i = j - 1
+	}
+	return buf.String()
+}
+
+// Description returns the HTML-escaped description of the page.
+// The description in the metadata is used if it exists, and the text before <!--more--> is used if the page has the marker.
+// Otherwise the text is truncated to MarkdownOptions.DescriptionLength characters.
+func (d *Document) Description() string {
+	if d.PageMeta.Description != "" {
+		return html.EscapeString(d.PageMeta.Description)
+	}
+	if d.Excerpt != "" {
+		return createDescriptionFromText(d.Excerpt, len([]rune(d.Excerpt)))
+	}
+	return createDescriptionFromText(d.Text, descriptionLength())
+}
+
+// escapeMarkdown escapes the ASCII punctuations in plain text with backslashes so that the text is not interpreted as markdown.
+func escapeMarkdown(text string) string {
+	var buf strings.Builder
+	for _, r := range text {
+		if r < utf8.RuneSelf && util.IsPunct(byte(r)) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
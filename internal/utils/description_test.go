@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_Description(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		md     string
+		want   string
+	}{
+		{
+			name: "Text",
+			md:   "{}\n---\n# T\n\n東京に\"行く\"。",
+			want: "東京に&#34;行く&#34;。",
+		},
+		{
+			name: "Line breaks",
+			md:   "{}\n---\n# T\n\nHello\nWorld  \nand\\\nthe   end.",
+			want: "Hello World and the end.",
+		},
+		{
+			name: "Paragraphs",
+			md:   "{}\n---\n# T\n\nThe end.\n\n## Next\n\n- a\n- b",
+			want: "The end. Next a b",
+		},
+		{
+			name: "Japanese line breaks",
+			md:   "{}\n---\n# T\n\n東京に\n行く。\n\n大阪\nOsaka",
+			want: "東京に行く。大阪 Osaka",
+		},
+		{
+			name: "Empty",
+			md:   "{}\n---\n# T",
+			want: "",
+		},
+		{
+			name: "Default length",
+			md:   "{}\n---\n# T\n\n" + strings.Repeat("あ", 500),
+			want: strings.Repeat("あ", 300),
+		},
+		{
+			name: "Character references",
+			md:   "{}\n---\n# T\n\n" + strings.Repeat("a", 299) + "&amp;b",
+			want: strings.Repeat("a", 299) + "&amp;",
+		},
+		{
+			name:   "Length",
+			length: 5,
+			md:     "{}\n---\n# T\n\n東京&大阪に行く。",
+			want:   "東京&amp;大阪",
+		},
+		{
+			name:   "More marker",
+			length: 5,
+			md:     "{}\n---\n# T\n\n東京と大阪に行く。\n\n<!--more-->\n\n京都にも行く。",
+			want:   "東京と大阪に行く。",
+		},
+		{
+			name: "Metadata",
+			md:   "{\"description\":\"<東京>\"}\n---\n# T\n\n大阪に行く。\n\n<!-- more -->",
+			want: "&lt;東京&gt;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureMarkdown(MarkdownOptions{DescriptionLength: tt.length})
+			defer ConfigureMarkdown(MarkdownOptions{})
+			doc, err := ParseDocument("a.md", []byte(tt.md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Description(); got != tt.want {
+				t.Errorf("Document.Description() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateIndexPage_Descriptions(t *testing.T) {
+	items := []IndexItem{{Name: "C", Pages: []IndexItemPage{{Title: "T", URL: "/t.html", Description: "*東京* &amp; [大阪]"}}}}
+	got, err := CreateIndexPage(BODY, "", "H", "", "", "", "", items, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<p>*東京* &amp; [大阪]</p>"; !strings.Contains(got, want) {
+		t.Errorf("CreateIndexPage() = %v, want %v", got, want)
+	}
+	got, err = CreateIndexPage(BODY, "", "H", "", "", "", "", items, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "東京") {
+		t.Errorf("CreateIndexPage() = %v, want no description", got)
+	}
+}
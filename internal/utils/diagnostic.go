@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Diagnostic represents a problem found in a file.
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+	// Rule is the name of the lint rule that found the problem.
+	Rule string `json:"rule,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Rule != "" {
+		return fmt.Sprintf("%s:%d: %s (%s)", d.File, d.Line, d.Message, d.Rule)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
+
+// SortDiagnostics sorts diagnostics by file name and line number.
+func SortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].File != diagnostics[j].File {
+			return diagnostics[i].File < diagnostics[j].File
+		}
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+}
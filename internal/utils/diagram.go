@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// diagramLanguages maps the languages of code blocks to the kinds of diagrams.
+var diagramLanguages = map[string]string{
+	"mermaid":  "mermaid",
+	"plantuml": "plantuml",
+	"puml":     "plantuml",
+}
+
+// diagramClassPattern matches the class of a diagram container.
+var diagramClassPattern = regexp.MustCompile(`^diagram diagram-(mermaid|plantuml)$`)
+
+// diagramKind returns the kind of the diagram of a code block, or "" if the code block is not a diagram.
+func diagramKind(block *ast.FencedCodeBlock, source []byte) string {
+	if block.Info == nil {
+		return ""
+	}
+	ci, err := parseCodeInfo(string(block.Info.Segment.Value(source)))
+	if err != nil {
+		return ""
+	}
+	return diagramLanguages[ci.Language]
+}
+
+// runDiagramCommand runs a command that reads the source of a diagram from stdin and writes SVG to stdout.
+// Anything before the <svg> element such as an XML declaration is removed.
+func runDiagramCommand(command []string, code string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(code)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.Errorf("%s: %s: %s", strings.Join(command, " "), err, message)
+		}
+		return "", errors.Errorf("%s: %s", strings.Join(command, " "), err)
+	}
+	svg := stdout.String()
+	start := strings.Index(svg, "<svg")
+	if start < 0 {
+		return "", errors.Errorf("%s: no SVG is written", strings.Join(command, " "))
+	}
+	return strings.TrimSpace(svg[start:]), nil
+}
+
+// svgElements are the SVG elements that are kept in the pre-rendered diagrams.
+// foreignObject, which can contain HTML, script and the elements that load external resources such as image are removed.
+var svgElements = []string{
+	"svg", "g", "defs", "symbol", "use", "title", "desc", "marker", "clipPath", "mask", "pattern",
+	"linearGradient", "radialGradient", "stop", "path", "rect", "circle", "ellipse", "line", "polyline", "polygon",
+	"text", "tspan", "textPath",
+}
+
+// svgAttributes are the attributes of the SVG elements that are kept in the pre-rendered diagrams.
+// The event handler attributes such as onload are removed.
+var svgAttributes = []string{
+	"id", "class", "x", "y", "x1", "y1", "x2", "y2", "cx", "cy", "r", "rx", "ry", "dx", "dy", "width", "height",
+	"viewBox", "preserveAspectRatio", "d", "points", "transform", "fill", "fill-opacity", "fill-rule", "stroke",
+	"stroke-width", "stroke-opacity", "stroke-dasharray", "stroke-linecap", "stroke-linejoin", "stroke-miterlimit",
+	"opacity", "font-family", "font-size", "font-style", "font-weight", "text-anchor", "dominant-baseline",
+	"alignment-baseline", "text-decoration", "letter-spacing", "lengthAdjust", "textLength", "clip-path", "clip-rule",
+	"mask", "marker-start", "marker-mid", "marker-end", "markerWidth", "markerHeight", "markerUnits", "refX", "refY",
+	"orient", "offset", "stop-color", "stop-opacity", "gradientUnits", "gradientTransform", "patternUnits",
+	"clipPathUnits", "maskUnits", "xmlns", "xmlns:xlink", "version", "role", "aria-label", "aria-roledescription",
+	"visibility", "display", "rotate",
+}
+
+var (
+	// svgValuePattern matches the values of the SVG attributes. Parentheses may contain only simple values
+	// such as "translate(10, 20)" and "url(#arrow)", so that no external resource is referenced.
+	svgValuePattern = regexp.MustCompile(`^(?:[\w\s#.,%:/+-]|\([\w\s#.,%+-]*\))*$`)
+	// svgStylePattern matches the inline styles of the SVG elements such as "fill:#f9f;stroke:#333".
+	svgStylePattern = regexp.MustCompile(`^(?:[\w\s#.,%:;!"'+-]|\([\w\s#.,%+-]*\))*$`)
+)
+
+// newSVGPolicy creates the policy that sanitizes the SVG of the pre-rendered diagrams.
+// Because the labels of diagrams are written by the authors of pages, the SVG is not trusted.
+// The <style> elements are kept only if raw HTML is enabled by MarkdownOptions.Unsafe, because their rules apply to the whole page.
+func newSVGPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowNoAttrs().OnElements(svgElements...)
+	p.AllowAttrs(svgAttributes...).Matching(svgValuePattern).OnElements(svgElements...)
+	p.AllowAttrs("style").Matching(svgStylePattern).OnElements(svgElements...)
+	p.AllowAttrs("href", "xlink:href").Matching(regexp.MustCompile(`^#[\w.:-]+$`)).OnElements("use", "textPath")
+	p.AllowRelativeURLs(true)
+	p.SkipElementsContent("foreignObject", "script")
+	if markdownOptions.Unsafe {
+		p.AllowUnsafe(true)
+		p.AllowElements("style")
+	} else {
+		p.SkipElementsContent("style")
+	}
+	return p
+}
+
+// sanitizeSVG removes the scripts, the event handlers, the HTML in foreignObject and the references to external resources from SVG.
+func sanitizeSVG(svg string) string {
+	return strings.TrimSpace(newSVGPolicy().Sanitize(svg))
+}
+
+// prepareDiagrams pre-renders the diagrams that have commands in MarkdownOptions.DiagramCommands.
+// The SVG is sanitized by the SVG policy, and is inserted into the page by insertDiagrams after the page is sanitized,
+// because the policy of the page does not allow SVG.
+// If any diagram is left to the script, HasDiagrams is set to true.
+// If a diagram has no command and MarkdownOptions.DiagramScript is empty, its source is shown as it is and a diagnostic is added.
+func (d *Document) prepareDiagrams() error {
+	return ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		block, ok := n.(*ast.FencedCodeBlock)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		kind := diagramKind(block, d.Source)
+		if kind == "" {
+			return ast.WalkSkipChildren, nil
+		}
+		command, exists := markdownOptions.DiagramCommands[kind]
+		if !exists || len(command) == 0 {
+			if markdownOptions.DiagramScript == "" {
+				d.Diagnostics = append(d.Diagnostics, Diagnostic{
+					File:    d.FileName,
+					Line:    d.lineAt(block.Info.Segment.Start),
+					Message: fmt.Sprintf("%s diagram is shown as its source because neither DIAGRAM_SCRIPT nor DIAGRAM_COMMAND_%s is set", kind, strings.ToUpper(kind)),
+				})
+				return ast.WalkSkipChildren, nil
+			}
+			d.HasDiagrams = true
+			return ast.WalkSkipChildren, nil
+		}
+		svg, err := runDiagramCommand(command, includedCode(block, d.Source))
+		if err != nil {
+			return ast.WalkStop, errors.Errorf("%s:%d: %s", d.FileName, d.lineAt(block.Info.Segment.Start), err)
+		}
+		svg = sanitizeSVG(svg)
+		placeholder := trustedPlaceholder("DIAGRAM", svg)
+		if d.diagrams == nil {
+			d.diagrams = map[string]string{}
+		}
+		d.diagrams[placeholder] = svg
+		block.SetAttributeString("diagram", []byte(placeholder))
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// insertDiagrams replaces the placeholders of the pre-rendered diagrams in the sanitized page with their sanitized SVG.
+func (d *Document) insertDiagrams(html string) string {
+	for placeholder, svg := range d.diagrams {
+		html = strings.ReplaceAll(html, placeholder, svg)
+	}
+	return html
+}
+
+// renderDiagram renders a diagram as a container.
+// A pre-rendered diagram is rendered as the placeholder of its SVG, and the other diagrams are rendered as their source for the script.
+func renderDiagram(w util.BufWriter, node *ast.FencedCodeBlock, kind, code string) error {
+	if placeholder, exists := node.AttributeString("diagram"); exists {
+		_, err := fmt.Fprintf(w, `<div class="diagram diagram-%s">%s</div>`+"\n", kind, placeholder.([]byte))
+		return err
+	}
+	_, err := fmt.Fprintf(w, `<pre class="diagram diagram-%s">%s</pre>`+"\n", kind, util.EscapeHTML([]byte(code)))
+	return err
+}
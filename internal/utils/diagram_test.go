@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderDiagram(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{
+			name: "Mermaid",
+			md:   "```mermaid\ngraph TD; A-->B\n```",
+			want: "<pre class=\"diagram diagram-mermaid\">graph TD; A--&gt;B\n</pre>\n",
+		},
+		{
+			name: "PlantUML",
+			md:   "```puml title=\"A\"\nA -> B\n```",
+			want: "<pre class=\"diagram diagram-plantuml\">A -&gt; B\n</pre>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+			if sanitized := CreateHTML(BODY, "", got, "", "", "", "", "", ""); sanitized != got {
+				t.Errorf("CreateHTML() = %v, want %v", sanitized, got)
+			}
+		})
+	}
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	svg := `<svg viewBox="0 0 10 10" onload="x"><style>text{fill:red}</style><defs><marker id="arrow"><path d="M0,0L10,5z"/></marker></defs>` +
+		`<g transform="translate(1, 2)"><rect fill="url(https://example.com/)" x="1"/><path marker-end="url(#arrow)" style="stroke:#333;"/>` +
+		`<use href="https://example.com/a.svg#b"/><image href="https://example.com/a.png"/></g></svg>`
+	want := `<svg viewbox="0 0 10 10"><defs><marker id="arrow"><path d="M0,0L10,5z"/></marker></defs>` +
+		`<g transform="translate(1, 2)"><rect x="1"/><path marker-end="url(#arrow)" style="stroke:#333;"/><use/></g></svg>`
+	if got := sanitizeSVG(svg); got != want {
+		t.Errorf("sanitizeSVG() = %v, want %v", got, want)
+	}
+
+	// <style> is kept only if raw HTML is enabled.
+	ConfigureMarkdown(MarkdownOptions{Unsafe: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	if got, want := sanitizeSVG(svg), `<style>text{fill:red}</style>`; !strings.Contains(got, want) {
+		t.Errorf("sanitizeSVG() = %v, want %v", got, want)
+	}
+}
+
+func TestDocument_PrepareDiagrams(t *testing.T) {
+	ConfigureMarkdown(MarkdownOptions{DiagramScript: "mermaid.min.js", DiagramCommands: map[string][]string{
+		"mermaid": {"sh", "-c", `cat > /dev/null; printf '<?xml version="1.0"?>\n<svg onload="x"><script>alert(1)</script><foreignObject><div><img src="a" onerror="x">B</div></foreignObject><text x="1">A</text></svg>\n'`},
+	}})
+	defer ConfigureMarkdown(MarkdownOptions{})
+
+	md := "{}\n---\n# A\n\n```mermaid\ngraph TD; A-->B\n```\n"
+	doc, err := ParseDocument("a.md", []byte(md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.prepareDiagrams(); err != nil {
+		t.Errorf("Document.prepareDiagrams() error = %v", err)
+		return
+	}
+	if doc.HasDiagrams {
+		t.Errorf("Document.HasDiagrams = true, want false")
+	}
+	got, err := CreatePage(BODY, doc, "", "", "", "", TOCOptions{MinLevel: DEFAULT_TOC_MIN, MaxLevel: DEFAULT_TOC_MAX})
+	if err != nil {
+		t.Errorf("CreatePage() error = %v", err)
+		return
+	}
+	if want := `<div class="diagram diagram-mermaid"><svg><text x="1">A</text></svg></div>`; !strings.Contains(got, want) {
+		t.Errorf("CreatePage() = %v, want %v", got, want)
+	}
+	for _, unsafe := range []string{"onload", "script", "foreignObject", "onerror"} {
+		if strings.Contains(got, unsafe) {
+			t.Errorf("CreatePage() = %v, want no %v", got, unsafe)
+		}
+	}
+
+	// The placeholder is not broken by tate-chu-yoko in vertical pages.
+	doc.PageMeta.WritingMode = WRITING_MODE_VERTICAL
+	got, err = CreatePage(BODY, doc, "", "", "", "", TOCOptions{MinLevel: DEFAULT_TOC_MIN, MaxLevel: DEFAULT_TOC_MAX})
+	if err != nil {
+		t.Errorf("CreatePage() error = %v", err)
+		return
+	}
+	if want := `<div class="diagram diagram-mermaid"><svg><text x="1">A</text></svg></div>`; !strings.Contains(got, want) {
+		t.Errorf("CreatePage() = %v, want %v", got, want)
+	}
+
+	doc, err = ParseDocument("a.md", []byte(md+"\n```plantuml\nA -> B\n```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.prepareDiagrams(); err != nil || !doc.HasDiagrams {
+		t.Errorf("Document.prepareDiagrams() error = %v, HasDiagrams = %v", err, doc.HasDiagrams)
+	}
+
+	ConfigureMarkdown(MarkdownOptions{DiagramCommands: map[string][]string{"mermaid": {"false"}}})
+	doc, err = ParseDocument("a.md", []byte(md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.prepareDiagrams()
+	if want := "a.md:5: false: exit status 1"; err == nil || err.Error() != want {
+		t.Errorf("Document.prepareDiagrams() error = %v, want %v", err, want)
+	}
+
+	// A diagram that has neither a command nor the script is shown as its source with a diagnostic.
+	ConfigureMarkdown(MarkdownOptions{})
+	doc, err = ParseDocument("a.md", []byte("{}\n---\n# A\n\n```plantuml\nA -> B\n```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.prepareDiagrams(); err != nil || doc.HasDiagrams {
+		t.Errorf("Document.prepareDiagrams() error = %v, HasDiagrams = %v", err, doc.HasDiagrams)
+	}
+	wantDiagnostics := []Diagnostic{{File: "a.md", Line: 5, Message: "plantuml diagram is shown as its source because neither DIAGRAM_SCRIPT nor DIAGRAM_COMMAND_PLANTUML is set"}}
+	if !reflect.DeepEqual(doc.Diagnostics, wantDiagnostics) {
+		t.Errorf("Document.Diagnostics = %v, want %v", doc.Diagnostics, wantDiagnostics)
+	}
+	got, err = CreatePage(BODY, doc, "", "", "", "", TOCOptions{MinLevel: DEFAULT_TOC_MIN, MaxLevel: DEFAULT_TOC_MAX})
+	if err != nil {
+		t.Errorf("CreatePage() error = %v", err)
+		return
+	}
+	if want := `<pre class="diagram diagram-plantuml">A -&gt; B` + "\n</pre>"; !strings.Contains(got, want) {
+		t.Errorf("CreatePage() = %v, want %v", got, want)
+	}
+}
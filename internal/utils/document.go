@@ -0,0 +1,447 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"golang.org/x/sync/errgroup"
+)
+
+// Heading represents a heading in a markdown document.
+type Heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// Document represents a markdown file that has been parsed into a goldmark AST.
+// The title, headings and text are extracted from the AST when it is parsed,
+// and the AST is reused to render the HTML.
+type Document struct {
+	FileName string
+	PageMeta PageMeta
+	Source   []byte
+	Root     ast.Node
+	Title    string
+	Headings []Heading
+	Text     string // Text is the plain text of the body except the title and code blocks.
+	// Excerpt is the plain text before the <!--more--> marker, or "" if there is no marker.
+	Excerpt string
+	// Inputs holds the files that the page is generated from, that is the markdown file and the files included by it.
+	Inputs []string
+	// Diagnostics holds the problems found while the document is loaded.
+	Diagnostics []Diagnostic
+	// HasDiagrams is true if the page has diagrams that are rendered by the diagram script.
+	HasDiagrams bool
+	// History is the history of the markdown file in the git repository, or nil if it is not read.
+	History *GitHistory
+	tocs    []*TOC
+	// diagrams maps the placeholders of the pre-rendered diagrams to their SVG.
+	diagrams map[string]string
+	// shortcodes maps the placeholders of the output of the trusted shortcodes to the output.
+	shortcodes map[string]string
+	// lineOffset is the number of lines before the markdown text in the file.
+	lineOffset int
+	// lineMap maps each line of the markdown text to the line in the file.
+	// The lines of included files are mapped to the line of the include directive.
+	lineMap []int
+}
+
+// splitContent splits the content of a markdown file into the metadata and the markdown text.
+// The content is split by "---", where the first part is interpreted as JSON format metadata, and the second part as markdown text.
+// It also returns the number of lines before the markdown text.
+func splitContent(content string) (PageMeta, string, int, error) {
+	pm := PageMeta{}
+	loc := SEPARATOR.FindStringIndex(content)
+	if loc == nil {
+		return pm, "", 0, errors.WithStack(errors.New("invalid content format"))
+	}
+	err := json.Unmarshal([]byte(content[:loc[0]]), &pm)
+	if err != nil {
+		return pm, "", 0, errors.WithStack(err)
+	}
+	rest := content[loc[1]:]
+	md := strings.TrimSpace(rest)
+	lineOffset := strings.Count(content[:len(content)-len(strings.TrimLeftFunc(rest, unicode.IsSpace))], "\n")
+	return pm, md, lineOffset, nil
+}
+
+// metadataLine returns the line number of a key in the metadata of a markdown file.
+// If the key is not found, it returns the first line.
+func metadataLine(content, key string) int {
+	loc := SEPARATOR.FindStringIndex(content)
+	if loc == nil {
+		return 1
+	}
+	i := strings.Index(content[:loc[0]], `"`+key+`"`)
+	if i == -1 {
+		return 1
+	}
+	return strings.Count(content[:i], "\n") + 1
+}
+
+// invalidUTF8Index returns the index of the first byte that is not valid UTF-8, or -1 if text is valid UTF-8.
+func invalidUTF8Index(text string) int {
+	for i, r := range text {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(text[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// nodeText returns the plain text of a node and its children.
+// Unlike ast.Node.Text, backslash escapes and character references are resolved,
+// and the line breaks and the boundaries of the blocks are written as newlines.
+func nodeText(node ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if n != node && n.Type() == ast.TypeBlock && buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			value := n.Segment.Value(source)
+			if !n.IsRaw() {
+				value = util.ResolveNumericReferences(util.ResolveEntityNames(util.UnescapePunctuations(value)))
+			}
+			buf.Write(value)
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				buf.WriteByte('\n')
+			}
+		case *ast.String:
+			buf.Write(n.Value)
+		case *ast.AutoLink:
+			buf.Write(n.Label(source))
+		case *ast.Image, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return buf.String()
+}
+
+// createHeadingID generates the ID of a heading from its text.
+func createHeadingID(text string) string {
+	if markdownOptions.HeadingID == HEADING_ID_ASCII {
+		return CreateSlug(text)
+	}
+	return CreateHash(text)
+}
+
+// uniqueID returns id if it is not used. Otherwise it appends the smallest number that makes it unique, such as "id-1".
+func uniqueID(id string, used map[string]bool) string {
+	if !used[id] {
+		return id
+	}
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", id, i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// assignHeadingIDs sets unique IDs to the "id" attributes of the heading nodes, and returns the headings.
+// An ID written as {#custom-id} is used as it is. The other IDs are generated from the text of the headings,
+// and the numbers are appended to them if the same IDs are already used in the document.
+func assignHeadingIDs(nodes []*ast.Heading, source []byte) []Heading {
+	// {#custom-id}で指定されたIDは自動生成されるIDより優先する
+	used := map[string]bool{}
+	for _, n := range nodes {
+		if id, exists := n.AttributeString("id"); exists {
+			used[string(id.([]byte))] = true
+		}
+	}
+
+	assigned := map[string]bool{}
+	headings := make([]Heading, len(nodes))
+	for i, n := range nodes {
+		id := ""
+		if value, exists := n.AttributeString("id"); exists {
+			id = string(value.([]byte))
+			if assigned[id] {
+				id = uniqueID(id, used)
+			}
+		} else {
+			id = uniqueID(createHeadingID(string(n.Text(source))), used)
+		}
+		used[id] = true
+		assigned[id] = true
+		n.SetAttributeString("id", []byte(id))
+		headings[i] = Heading{Level: n.Level, ID: id, Text: nodeText(n, source)}
+	}
+	return headings
+}
+
+// extract collects the title, headings and text from the AST.
+// The ID of each heading is set to the "id" attribute of the heading node so that
+// the rendered headings and the header list always agree.
+func (d *Document) extract() {
+	d.tocs = replaceTOCMarkers(d.Root, d.Source)
+	var first, title *ast.Heading
+	headings := []*ast.Heading{}
+	_ = ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		headings = append(headings, heading)
+		if first == nil {
+			first = heading
+		}
+		if title == nil && heading.Level == 1 {
+			title = heading
+		}
+		return ast.WalkContinue, nil
+	})
+
+	d.Headings = assignHeadingIDs(headings, d.Source)
+
+	// 最初のh1をタイトルとし、h1がなければ最初の見出しをタイトルとする
+	if title == nil {
+		title = first
+	}
+	if title != nil {
+		d.Title = nodeText(title, d.Source)
+	}
+
+	var body strings.Builder
+	for c := d.Root.FirstChild(); c != nil; c = c.NextSibling() {
+		if isMoreMarker(c, d.Source) && d.Excerpt == "" {
+			d.Excerpt = body.String()
+			continue
+		}
+		switch c.Kind() {
+		case ast.KindFencedCodeBlock, ast.KindCodeBlock, ast.KindHTMLBlock:
+			continue
+		}
+		if title != nil && c == ast.Node(title) {
+			continue
+		}
+		if body.Len() > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString(nodeText(c, d.Source))
+	}
+	d.Text = body.String()
+}
+
+// ParseMarkdown parses markdown text into a Document.
+func ParseMarkdown(md string) *Document {
+	source := []byte(md)
+	doc := &Document{
+		Source: source,
+		Root:   markdown.Parser().Parse(text.NewReader(source)),
+	}
+	doc.extract()
+	return doc
+}
+
+// ParseDocument parses the content of a markdown file into a Document.
+// The include directives in the markdown text are expanded before it is parsed.
+func ParseDocument(markDownFileName string, content []byte) (*Document, error) {
+	pm, md, lineOffset, err := splitContent(string(content))
+	if err != nil {
+		return nil, errors.WithMessage(err, markDownFileName)
+	}
+	if key, err := validatePageTOCLevels(pm); err != nil {
+		return nil, errors.WithMessagef(err, "%s:%d", markDownFileName, metadataLine(string(content), key))
+	}
+	md, lineMap, lineFiles, inputs, err := expandIncludes(markDownFileName, md, lineOffset, []string{filepath.Clean(markDownFileName)})
+	if err != nil {
+		return nil, err
+	}
+	// 不正なUTF-8はgoldmarkのCJK拡張などをpanicさせるので、パースする前にエラーにする
+	if i := invalidUTF8Index(md); i != -1 {
+		line := strings.Count(md[:i], "\n")
+		return nil, errors.Errorf("%s:%d: invalid UTF-8", markDownFileName, lineMap[line])
+	}
+	doc := ParseMarkdown(md)
+	doc.FileName = markDownFileName
+	doc.Inputs = append([]string{markDownFileName}, inputs...)
+	doc.PageMeta = pm
+	doc.lineOffset = lineOffset
+	doc.lineMap = lineMap
+	doc.rebaseIncludes(lineFiles)
+	if err := doc.validateCodeInfo(); err != nil {
+		return nil, err
+	}
+	if err := doc.prepareShortcodes(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// URL returns the URL of the page generated from the document.
+// The URLs of the pages in the languages other than the default language are prefixed with the language such as "/en".
+func (d *Document) URL(sourceDir, baseURL string) string {
+	lang, dir, name := translationPath(d.FileName, sourceDir)
+	return CreateURL(dir, name, sourceDir, baseURL+LanguagePrefix(lang))
+}
+
+// Line returns the line number of a node in the markdown file.
+// For an inline node, it returns the line of its first text. Otherwise it returns the first line of the node.
+func (d *Document) Line(node ast.Node) int {
+	return d.lineAt(d.nodeOffset(node))
+}
+
+// nodeOffset returns the offset of a node in the markdown text, or -1 if the node has no position.
+func (d *Document) nodeOffset(node ast.Node) int {
+	offset := -1
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering {
+			offset = t.Segment.Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	for n := node; offset < 0 && n != nil; n = n.Parent() {
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			offset = n.Lines().At(0).Start
+		}
+	}
+	return offset
+}
+
+// lineAt returns the line number in the file of an offset in the markdown text.
+// If offset is negative, it returns the first line of the markdown text.
+func (d *Document) lineAt(offset int) int {
+	if offset < 0 {
+		return d.lineOffset + 1
+	}
+	line := bytes.Count(d.Source[:offset], []byte("\n"))
+	if line < len(d.lineMap) {
+		return d.lineMap[line]
+	}
+	return d.lineOffset + line + 1
+}
+
+// SetTOC sets the HTML of the table of contents that replaces the "[[toc]]" markers.
+func (d *Document) SetTOC(html string) {
+	for _, toc := range d.tocs {
+		toc.HTML = html
+	}
+}
+
+// HTML renders the AST of the document to HTML.
+func (d *Document) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, d.Source, d.Root); err != nil {
+		if d.FileName != "" {
+			return "", errors.Wrap(err, d.FileName)
+		}
+		return "", errors.WithStack(err)
+	}
+	return buf.String(), nil
+}
+
+var parentPathRe = regexp.MustCompile(`^(\.\./)+`)
+
+func isLocalImage(imageDir, destination string) bool {
+	destination = parentPathRe.ReplaceAllString(destination, "")
+	return strings.HasPrefix(destination, imageDir+"/")
+}
+
+// measureImages sets the sizes of the local images to the "width" and "height" attributes of the image nodes.
+// A local image that cannot be read is added to the diagnostics.
+func (d *Document) measureImages(sourceDir string) {
+	_ = ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		image, ok := n.(*ast.Image)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		destination := string(image.Destination)
+		if !isLocalImage(IMAGE_DIR, destination) {
+			return ast.WalkContinue, nil
+		}
+		imagePath := parentPathRe.ReplaceAllString(destination, "")
+		path := filepath.Join(sourceDir, imagePath)
+		width, height, err := ImageSize(path)
+		if err != nil {
+			d.Diagnostics = append(d.Diagnostics, Diagnostic{
+				File:    d.FileName,
+				Line:    d.Line(image),
+				Message: fmt.Sprintf("%s does not exist", path),
+			})
+			return ast.WalkContinue, nil
+		}
+		image.SetAttributeString("width", width)
+		image.SetAttributeString("height", height)
+		return ast.WalkContinue, nil
+	})
+}
+
+// createDocumentTask returns a task that reads and parses a specified markdown file.
+func createDocumentTask(markDownFileName, sourceDir string, docs []*Document, index int) func() error {
+	return func() error {
+		content, err := os.ReadFile(markDownFileName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		doc, err := ParseDocument(markDownFileName, content)
+		if err != nil {
+			return err
+		}
+		if err := doc.includeCode(); err != nil {
+			return err
+		}
+		if err := doc.prepareDiagrams(); err != nil {
+			return err
+		}
+		doc.measureImages(sourceDir)
+		docs[index] = doc
+		return nil
+	}
+}
+
+// ParseDocuments reads and parses markdown files.
+// Unlike LoadDocuments, the code is not included and the diagrams are not rendered, so it is used to inspect the markdown text.
+func ParseDocuments(markDownFileNames []string) ([]*Document, error) {
+	docs := make([]*Document, len(markDownFileNames))
+	for i, fileName := range markDownFileNames {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		docs[i], err = ParseDocument(fileName, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// LoadDocuments asynchronously reads and parses multiple markdown files.
+// Each file is read and parsed only once.
+func LoadDocuments(markDownFileNames []string, sourceDir string) ([]*Document, error) {
+	var g errgroup.Group
+	docs := make([]*Document, len(markDownFileNames))
+
+	for i, fileName := range markDownFileNames {
+		task := createDocumentTask(fileName, sourceDir, docs, i)
+		g.Go(task)
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	type args struct {
+		content string
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantTitle    string
+		wantHeadings []Heading
+		wantText     string
+		wantErr      bool
+	}{
+		{
+			name:      "Headings in code blocks are ignored",
+			args:      args{content: "{}\n---\n# Title\n\n```\n# not heading\n```\n\n~~~\n## not heading\n~~~\n\n## Section\n\ntext"},
+			wantTitle: "Title",
+			wantHeadings: []Heading{
+				{Level: 1, ID: "Title", Text: "Title"},
+				{Level: 2, ID: "Section", Text: "Section"},
+			},
+			wantText: "Section\ntext",
+		},
+		{
+			name:      "Setext headings",
+			args:      args{content: "{}\n---\nTitle\n=====\n\nSection\n-------\n"},
+			wantTitle: "Title",
+			wantHeadings: []Heading{
+				{Level: 1, ID: "Title", Text: "Title"},
+				{Level: 2, ID: "Section", Text: "Section"},
+			},
+			wantText: "Section",
+		},
+		{
+			name:      "Inline markup in headings",
+			args:      args{content: "{}\n---\n# `go` *Title* &amp; [link](a.md)\n\n![image](images/a.png)"},
+			wantTitle: "go Title & link",
+			wantHeadings: []Heading{
+				{Level: 1, ID: "go_Title__amp;_link", Text: "go Title & link"},
+			},
+		},
+		{
+			name:    "Invalid metadata",
+			args:    args{content: "{\n---\n# Title"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDocument("test.md", []byte(tt.args.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDocument() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Title != tt.wantTitle {
+				t.Errorf("ParseDocument() Title = %v, want %v", got.Title, tt.wantTitle)
+			}
+			if !reflect.DeepEqual(got.Headings, tt.wantHeadings) {
+				t.Errorf("ParseDocument() Headings = %v, want %v", got.Headings, tt.wantHeadings)
+			}
+			if got.Text != tt.wantText {
+				t.Errorf("ParseDocument() Text = %v, want %v", got.Text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestAssignHeadingIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		md        string
+		headingID string
+		want      []string
+	}{
+		{
+			name: "Duplicated headings",
+			md:   "# Title\n\n## Example\n\n## Example\n\n## Example",
+			want: []string{"Title", "Example", "Example-1", "Example-2"},
+		},
+		{
+			name: "Custom IDs",
+			md:   "# Title\n\n## Example\n\n## Other {#Example}\n\n## Custom {#custom}\n\n## Custom {#custom}",
+			want: []string{"Title", "Example-1", "Example", "custom", "custom-1"},
+		},
+		{
+			name:      "ASCII slugs",
+			md:        "# はじめに\n\n## Hello World\n\n## Hello world",
+			headingID: HEADING_ID_ASCII,
+			want:      []string{"section", "hello-world", "hello-world-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureMarkdown(MarkdownOptions{HeadingID: tt.headingID})
+			defer ConfigureMarkdown(MarkdownOptions{})
+			doc := ParseMarkdown(tt.md)
+			got, _ := Map(doc.Headings, func(heading Heading, _ int) (string, error) {
+				return heading.ID, nil
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.Headings IDs = %v, want %v", got, tt.want)
+			}
+			html, err := doc.HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			for _, id := range tt.want {
+				if !strings.Contains(html, fmt.Sprintf(`id="%s"`, id)) {
+					t.Errorf("Document.HTML() = %v, want id %v", html, id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DEFAULT_EXTERNAL_LINK_CONCURRENCY   = 8
+	DEFAULT_EXTERNAL_LINK_HOST_INTERVAL = time.Second
+	DEFAULT_EXTERNAL_LINK_TIMEOUT       = 10 * time.Second
+	DEFAULT_EXTERNAL_LINK_CACHE_TTL     = 24 * time.Hour
+	DEFAULT_EXTERNAL_LINK_CACHE_FILE    = ".mujidoc-link-cache.json"
+)
+
+// IHTTPClient is the interface of the HTTP client used to check external links.
+// *http.Client satisfies it.
+type IHTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ExternalLinkResult is the result of checking an external link.
+type ExternalLinkResult struct {
+	StatusCode int       `json:"status,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// OK determines if the link is alive.
+func (r ExternalLinkResult) OK() bool {
+	return r.Error == "" && r.StatusCode >= 200 && r.StatusCode < 400
+}
+
+func (r ExternalLinkResult) String() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return fmt.Sprintf("status %d", r.StatusCode)
+}
+
+// ExternalLinkChecker checks external links with bounded concurrency and per-host rate limits.
+// The successful results are cached in CacheFile and reused until CacheTTL elapses.
+// The failed results are not cached, so that a temporary error such as a timeout is checked again in the next run.
+// The zero value checks the links with http.DefaultClient without a cache, a rate limit or concurrency. NewExternalLinkChecker sets the defaults.
+type ExternalLinkChecker struct {
+	Client       IHTTPClient
+	Concurrency  int              // Concurrency is the maximum number of links checked at the same time.
+	HostInterval time.Duration    // HostInterval is the minimum interval between requests to the same host.
+	CacheFile    string           // CacheFile is the file the results are cached in. If empty, the results are not cached.
+	CacheTTL     time.Duration    // CacheTTL is how long a successful result is reused.
+	Ignore       []*regexp.Regexp // Ignore holds the patterns of URLs that are not checked.
+	// Allow holds the patterns of URLs that are checked. If it is empty, all the URLs that are not ignored are checked.
+	Allow []*regexp.Regexp
+
+	now   func() time.Time
+	mu    sync.Mutex
+	hosts map[string]time.Time // hosts maps a host to the time when the next request is allowed.
+}
+
+// NewExternalLinkChecker creates an ExternalLinkChecker with the default settings.
+func NewExternalLinkChecker(client IHTTPClient) *ExternalLinkChecker {
+	return &ExternalLinkChecker{
+		Client:       client,
+		Concurrency:  DEFAULT_EXTERNAL_LINK_CONCURRENCY,
+		HostInterval: DEFAULT_EXTERNAL_LINK_HOST_INTERVAL,
+		CacheFile:    DEFAULT_EXTERNAL_LINK_CACHE_FILE,
+		CacheTTL:     DEFAULT_EXTERNAL_LINK_CACHE_TTL,
+		now:          time.Now,
+		hosts:        map[string]time.Time{},
+	}
+}
+
+// matchesAny determines if a URL matches one of the patterns.
+func matchesAny(patterns []*regexp.Regexp, link string) bool {
+	for _, re := range patterns {
+		if re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnored determines if a URL is not checked.
+// A URL is ignored if it matches one of the ignore patterns, or if the allow patterns are set and it matches none of them.
+func (c *ExternalLinkChecker) isIgnored(link string) bool {
+	if matchesAny(c.Ignore, link) {
+		return true
+	}
+	return len(c.Allow) > 0 && !matchesAny(c.Allow, link)
+}
+
+// wait blocks until a request to the host is allowed.
+func (c *ExternalLinkChecker) wait(ctx context.Context, host string) error {
+	c.mu.Lock()
+	now := c.now()
+	next := c.hosts[host]
+	if next.Before(now) {
+		next = now
+	}
+	c.hosts[host] = next.Add(c.HostInterval)
+	c.mu.Unlock()
+
+	timer := time.NewTimer(next.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// request sends a request and discards the body of the response.
+func (c *ExternalLinkChecker) request(ctx context.Context, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "mujidoc")
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	return res.StatusCode, nil
+}
+
+// checkLink checks a link with a HEAD request.
+// Because some servers do not support HEAD, it falls back to a GET request if the HEAD request fails.
+func (c *ExternalLinkChecker) checkLink(ctx context.Context, link string) (ExternalLinkResult, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ExternalLinkResult{Error: err.Error(), CheckedAt: c.now()}, nil
+	}
+	if err := c.wait(ctx, u.Host); err != nil {
+		return ExternalLinkResult{}, errors.WithStack(err)
+	}
+	status, err := c.request(ctx, http.MethodHead, link)
+	if err == nil && status < 400 {
+		return ExternalLinkResult{StatusCode: status, CheckedAt: c.now()}, nil
+	}
+	if err := c.wait(ctx, u.Host); err != nil {
+		return ExternalLinkResult{}, errors.WithStack(err)
+	}
+	status, err = c.request(ctx, http.MethodGet, link)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ExternalLinkResult{}, errors.WithStack(ctx.Err())
+		}
+		return ExternalLinkResult{Error: err.Error(), CheckedAt: c.now()}, nil
+	}
+	return ExternalLinkResult{StatusCode: status, CheckedAt: c.now()}, nil
+}
+
+// loadCache reads the cached results. If the cache file does not exist, it returns an empty cache.
+func (c *ExternalLinkChecker) loadCache() (map[string]ExternalLinkResult, error) {
+	cache := map[string]ExternalLinkResult{}
+	if c.CacheFile == "" {
+		return cache, nil
+	}
+	content, err := os.ReadFile(c.CacheFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := json.Unmarshal(content, &cache); err != nil {
+		return nil, errors.Wrapf(err, "invalid cache file %s", c.CacheFile)
+	}
+	return cache, nil
+}
+
+// saveCache writes the results to the cache file.
+func (c *ExternalLinkChecker) saveCache(cache map[string]ExternalLinkResult) error {
+	if c.CacheFile == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(c.CacheFile, content, 0644))
+}
+
+// Check checks the links and returns the results of the links that are not ignored.
+// A cached result is reused if it is newer than CacheTTL. Only the successful results are cached.
+func (c *ExternalLinkChecker) Check(ctx context.Context, links []string) (map[string]ExternalLinkResult, error) {
+	// 構造体リテラルで作られた場合に備えて初期化する
+	c.mu.Lock()
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+	if c.hosts == nil {
+		c.hosts = map[string]time.Time{}
+	}
+	c.mu.Unlock()
+
+	cache, err := c.loadCache()
+	if err != nil {
+		return nil, err
+	}
+
+	results := map[string]ExternalLinkResult{}
+	targets := []string{}
+	for _, link := range links {
+		if c.isIgnored(link) {
+			continue
+		}
+		if cached, exists := cache[link]; exists && cached.OK() && c.now().Sub(cached.CheckedAt) < c.CacheTTL {
+			results[link] = cached
+			continue
+		}
+		targets = append(targets, link)
+	}
+
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(c.Concurrency, 1))
+	for _, link := range targets {
+		g.Go(func() error {
+			result, err := c.checkLink(ctx, link)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			results[link] = result
+			if result.OK() {
+				cache[link] = result
+			} else {
+				delete(cache, link)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return results, c.saveCache(cache)
+}
+
+// CollectExternalLinks collects the absolute http and https URLs in the HTML files in outputDir
+// that do not point to the site at baseURL. It returns the URLs and where they are referenced.
+func CollectExternalLinks(outputDir, baseURL string) (map[string][]Diagnostic, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	links := map[string][]Diagnostic{}
+	err = filepath.WalkDir(outputDir, func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() || filepath.Ext(fileName) != ".html" {
+			return nil
+		}
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, link := range parseHTMLFile(content).Links {
+			u, err := url.Parse(link.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == base.Host {
+				continue
+			}
+			u.Fragment = ""
+			links[u.String()] = append(links[u.String()], Diagnostic{File: fileName, Line: link.Line})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// CheckExternalLinks checks the external links in the HTML files in outputDir,
+// and reports the links that are broken.
+func CheckExternalLinks(ctx context.Context, checker *ExternalLinkChecker, outputDir, baseURL string) ([]Diagnostic, error) {
+	references, err := CollectExternalLinks(outputDir, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	links := Keys(references)
+	sort.Strings(links)
+	results, err := checker.Check(ctx, links)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := []Diagnostic{}
+	for _, link := range links {
+		result, exists := results[link]
+		if !exists || result.OK() {
+			continue
+		}
+		for _, reference := range references[link] {
+			reference.Message = fmt.Sprintf("%s: %s", link, result)
+			diagnostics = append(diagnostics, reference)
+		}
+	}
+	SortDiagnostics(diagnostics)
+	return diagnostics, nil
+}
+
+// ParsePatterns compiles patterns separated by commas.
+func ParsePatterns(patterns string) ([]*regexp.Regexp, error) {
+	res := []*regexp.Regexp{}
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newExternalLinkServer(requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestExternalLinkChecker_Check(t *testing.T) {
+	var requests int32
+	server := newExternalLinkServer(&requests)
+	defer server.Close()
+
+	checker := NewExternalLinkChecker(server.Client())
+	checker.HostInterval = 0
+	checker.CacheFile = filepath.Join(t.TempDir(), "cache.json")
+	checker.Ignore = []*regexp.Regexp{regexp.MustCompile(`/ignored$`)}
+
+	links := []string{server.URL + "/ok", server.URL + "/no-head", server.URL + "/missing", server.URL + "/ignored"}
+	got, err := checker.Check(context.Background(), links)
+	if err != nil {
+		t.Errorf("ExternalLinkChecker.Check() error = %v", err)
+		return
+	}
+	statuses := map[string]int{}
+	for link, result := range got {
+		statuses[link] = result.StatusCode
+	}
+	want := map[string]int{
+		server.URL + "/ok":      http.StatusOK,
+		server.URL + "/no-head": http.StatusOK,
+		server.URL + "/missing": http.StatusNotFound,
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("ExternalLinkChecker.Check() = %v, want %v", statuses, want)
+	}
+	// HEAD and GET are sent to /no-head and /missing.
+	if requests != 5 {
+		t.Errorf("requests = %v, want %v", requests, 5)
+	}
+
+	// The successful results are reused, and the failed result is checked again.
+	_, err = checker.Check(context.Background(), links)
+	if err != nil {
+		t.Errorf("ExternalLinkChecker.Check() error = %v", err)
+		return
+	}
+	if requests != 7 {
+		t.Errorf("requests = %v, want %v", requests, 7)
+	}
+
+	// The expired results are checked again.
+	checker.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	_, err = checker.Check(context.Background(), []string{server.URL + "/ok"})
+	if err != nil {
+		t.Errorf("ExternalLinkChecker.Check() error = %v", err)
+		return
+	}
+	if requests != 8 {
+		t.Errorf("requests = %v, want %v", requests, 8)
+	}
+}
+
+func TestExternalLinkChecker_Check_TemporaryError(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first HEAD and GET requests fail.
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := NewExternalLinkChecker(server.Client())
+	checker.HostInterval = 0
+	checker.CacheFile = filepath.Join(t.TempDir(), "cache.json")
+
+	links := []string{server.URL + "/flaky"}
+	for _, want := range []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK} {
+		got, err := checker.Check(context.Background(), links)
+		if err != nil {
+			t.Errorf("ExternalLinkChecker.Check() error = %v", err)
+			return
+		}
+		if got[links[0]].StatusCode != want {
+			t.Errorf("ExternalLinkChecker.Check() = %v, want %v", got[links[0]].StatusCode, want)
+		}
+	}
+	// The last result is read from the cache.
+	if requests != 3 {
+		t.Errorf("requests = %v, want %v", requests, 3)
+	}
+}
+
+func TestExternalLinkChecker_Check_Allow(t *testing.T) {
+	var requests int32
+	server := newExternalLinkServer(&requests)
+	defer server.Close()
+
+	// The zero value can be used without NewExternalLinkChecker.
+	checker := &ExternalLinkChecker{Client: server.Client()}
+	checker.Allow = []*regexp.Regexp{regexp.MustCompile(`/(ok|missing)$`)}
+	checker.Ignore = []*regexp.Regexp{regexp.MustCompile(`/missing$`)}
+
+	links := []string{server.URL + "/ok", server.URL + "/no-head", server.URL + "/missing"}
+	got, err := checker.Check(context.Background(), links)
+	if err != nil {
+		t.Errorf("ExternalLinkChecker.Check() error = %v", err)
+		return
+	}
+	// Only the allowed link that is not ignored is checked.
+	if len(got) != 1 || got[server.URL+"/ok"].StatusCode != http.StatusOK {
+		t.Errorf("ExternalLinkChecker.Check() = %v, want only %v", got, server.URL+"/ok")
+	}
+	if requests != 1 {
+		t.Errorf("requests = %v, want %v", requests, 1)
+	}
+}
+
+func TestExternalLinkChecker_Check_ZeroValue(t *testing.T) {
+	var requests int32
+	server := newExternalLinkServer(&requests)
+	defer server.Close()
+
+	// The zero value uses http.DefaultClient.
+	checker := &ExternalLinkChecker{}
+	links := []string{server.URL + "/ok", server.URL + "/missing"}
+	got, err := checker.Check(context.Background(), links)
+	if err != nil {
+		t.Errorf("ExternalLinkChecker.Check() error = %v", err)
+		return
+	}
+	if got[links[0]].StatusCode != http.StatusOK || got[links[1]].StatusCode != http.StatusNotFound {
+		t.Errorf("ExternalLinkChecker.Check() = %v", got)
+	}
+	if checker.Client != http.DefaultClient {
+		t.Errorf("ExternalLinkChecker.Client = %v, want %v", checker.Client, http.DefaultClient)
+	}
+}
+
+func TestCheckExternalLinks(t *testing.T) {
+	var requests int32
+	server := newExternalLinkServer(&requests)
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	content := "<html>\n<a href=\"" + server.URL + "/ok#section\">ok</a>\n<img src=\"" + server.URL + "/missing\">\n" +
+		"<a href=\"https://example.com/site/page.html\">internal</a>\n<a href=\"page.html\">relative</a>\n</html>"
+	if err := os.WriteFile(filepath.Join(outputDir, "index.html"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := NewExternalLinkChecker(server.Client())
+	checker.HostInterval = 0
+	checker.CacheFile = ""
+	got, err := CheckExternalLinks(context.Background(), checker, outputDir, "https://example.com/site")
+	if err != nil {
+		t.Errorf("CheckExternalLinks() error = %v", err)
+		return
+	}
+	want := []Diagnostic{
+		{File: filepath.Join(outputDir, "index.html"), Line: 3, Message: server.URL + "/missing: status 404"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckExternalLinks() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+)
+
+const (
+	DEFAULT_GIT_HISTORY_CACHE_FILE = ".mujidoc-git-cache.json"
+	// gitLogFormat starts each commit with a record separator, and separates the date and the author with a unit separator.
+	gitLogFormat = "%x1e%aI%x1f%an"
+	// gitRenameSimilarity is the similarity index with which a deleted and an added file are detected as a rename.
+	gitRenameSimilarity = "-M50%"
+)
+
+// GitHistory is the history of a markdown file in the git repository.
+type GitHistory struct {
+	Created time.Time `json:"created"` // Created is the date of the first commit of the file.
+	Updated time.Time `json:"updated"` // Updated is the date of the last commit of the file.
+	// Authors holds the distinct authors of the commits in the order of their first commits.
+	Authors []string `json:"authors"`
+}
+
+// GitHistories holds the histories of the files in the git repository at a commit.
+// The paths of the files are relative to the root of the repository such as "src/guide/page.md".
+type GitHistories struct {
+	Root  string                 `json:"root"` // Root is the root of the repository.
+	Head  string                 `json:"head"`
+	Files map[string]*GitHistory `json:"files"`
+}
+
+// runGit runs a git command in the current directory and returns its output.
+func runGit(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.Errorf("git %s: %s: %s", strings.Join(args, " "), err, message)
+		}
+		return "", errors.Errorf("git %s: %s", strings.Join(args, " "), err)
+	}
+	return stdout.String(), nil
+}
+
+// parseGitLog adds the commits in the output of "git log --reverse --name-status -M" to the histories of the files.
+// The commits must be in chronological order. The history of a renamed file is carried over to its new path,
+// and the history of a deleted file is removed.
+func parseGitLog(log string, files map[string]*GitHistory) error {
+	for _, record := range strings.Split(log, "\x1e") {
+		header, names, _ := strings.Cut(record, "\n")
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		date, author, found := strings.Cut(header, "\x1f")
+		if !found {
+			return errors.Errorf("invalid git log: %q", header)
+		}
+		committed, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, line := range strings.Split(names, "\n") {
+			if line == "" {
+				continue
+			}
+			fields := strings.Split(line, "\t")
+			status, name := fields[0], fields[len(fields)-1]
+			switch {
+			case len(fields) < 2:
+				return errors.Errorf("invalid git log: %q", line)
+			case status == "D":
+				delete(files, name)
+				continue
+			case strings.HasPrefix(status, "R") && len(fields) == 3:
+				// 名前が変わったファイルは元のファイルの履歴を引き継ぐ
+				if history, exists := files[fields[1]]; exists {
+					files[name] = history
+					delete(files, fields[1])
+				}
+			}
+			history, exists := files[name]
+			if !exists {
+				history = &GitHistory{Created: committed, Authors: []string{}}
+				files[name] = history
+			}
+			history.Updated = committed
+			if !slices.Contains(history.Authors, author) {
+				history.Authors = append(history.Authors, author)
+			}
+		}
+	}
+	return nil
+}
+
+// loadGitHistoryCache reads the cached histories. If the cache file does not exist, it returns nil.
+func loadGitHistoryCache(cacheFile string) (*GitHistories, error) {
+	if cacheFile == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(cacheFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	histories := &GitHistories{}
+	if err := json.Unmarshal(content, histories); err != nil {
+		return nil, errors.Wrapf(err, "invalid cache file %s", cacheFile)
+	}
+	return histories, nil
+}
+
+// LoadGitHistories reads the histories of the files under the current directory from the local git repository.
+// The histories are cached in cacheFile with the commit of HEAD. If HEAD has moved forward,
+// only the new commits are read. If cacheFile is "", the histories are not cached.
+func LoadGitHistories(cacheFile string) (*GitHistories, error) {
+	root, err := GitRoot()
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit("rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	head := strings.TrimSpace(out)
+	cache, err := loadGitHistoryCache(cacheFile)
+	if err != nil {
+		return nil, err
+	}
+	// 別のリポジトリのキャッシュは使わない
+	if cache != nil && (cache.Root != root || cache.Files == nil) {
+		cache = nil
+	}
+	if cache != nil && cache.Head == head {
+		return cache, nil
+	}
+
+	histories := &GitHistories{Root: root, Head: head, Files: map[string]*GitHistory{}}
+	revision := head
+	if cache != nil {
+		// HEADが進んだだけなら追加されたコミットだけを読む
+		if _, err := runGit("merge-base", "--is-ancestor", cache.Head, head); err == nil {
+			histories.Files = cache.Files
+			revision = cache.Head + ".." + head
+		}
+	}
+	out, err = runGit("-c", "core.quotepath=off", "log", "--reverse", "--name-status", gitRenameSimilarity, "--format="+gitLogFormat, revision, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	if err := parseGitLog(out, histories.Files); err != nil {
+		return nil, err
+	}
+
+	if cacheFile != "" {
+		content, err := json.MarshalIndent(histories, "", "  ")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := os.WriteFile(cacheFile, content, 0644); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return histories, nil
+}
+
+// Apply sets the histories of the documents. The documents that are not committed have no history,
+// and neither do the documents outside the repository.
+func (h *GitHistories) Apply(docs []*Document) {
+	for _, doc := range docs {
+		doc.History = nil
+		if path, ok := pathInRepository(h.Root, doc.FileName); ok {
+			doc.History = h.Files[path]
+		}
+	}
+}
+
+// Created returns the date of the first commit of the page, or the date in the metadata if the page has no history.
+// It returns the zero time if neither is available.
+func (d *Document) Created() time.Time {
+	if d.History != nil {
+		return d.History.Created
+	}
+	created, err := time.Parse(DateTime, d.PageMeta.Date)
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+// Updated returns the date of the last commit of the page, or Created if the page has no history.
+func (d *Document) Updated() time.Time {
+	if d.History != nil {
+		return d.History.Updated
+	}
+	return d.Created()
+}
+
+// timeElement returns the time element of a date, or "" if the date is the zero time.
+func timeElement(t time.Time, location *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+	t = t.In(location)
+	return fmt.Sprintf(`<time datetime="%s">%s</time>`, t.Format(time.RFC3339), t.Format(time.DateOnly))
+}
+
+// HistoryLayout replaces __CREATED__, __UPDATED__ and __AUTHORS__ in a layout for a page.
+// The authors are separated by commas. If doc is nil, they are replaced with "".
+func HistoryLayout(layout string, doc *Document, location *time.Location) string {
+	created, updated, authors := "", "", ""
+	if doc != nil {
+		created = timeElement(doc.Created(), location)
+		updated = timeElement(doc.Updated(), location)
+		if doc.History != nil {
+			authors = html.EscapeString(strings.Join(doc.History.Authors, ", "))
+		}
+	}
+	layout = strings.ReplaceAll(layout, CREATED, created)
+	layout = strings.ReplaceAll(layout, UPDATED, updated)
+	return strings.ReplaceAll(layout, AUTHORS, authors)
+}
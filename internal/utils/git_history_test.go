@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGitLog(t *testing.T) {
+	files := map[string]*GitHistory{}
+	log := "\x1e2024-01-01T09:00:00+09:00\x1fAlice\n\nA\tsrc/a.md\nA\tsrc/old.md\nA\tsrc/deleted.md\n" +
+		"\x1e2024-02-01T09:00:00+09:00\x1fBob\n\nM\tsrc/a.md\nR090\tsrc/old.md\tsrc/b.md\nD\tsrc/deleted.md\n" +
+		"\x1e2024-03-01T09:00:00+09:00\x1fAlice\n\nM\tsrc/a.md\n"
+	if err := parseGitLog(log, files); err != nil {
+		t.Fatal(err)
+	}
+	jst := time.FixedZone("", 9*60*60)
+	want := map[string]*GitHistory{
+		"src/a.md": {
+			Created: time.Date(2024, 1, 1, 9, 0, 0, 0, jst),
+			Updated: time.Date(2024, 3, 1, 9, 0, 0, 0, jst),
+			Authors: []string{"Alice", "Bob"},
+		},
+		// The history of a renamed file is carried over.
+		"src/b.md": {
+			Created: time.Date(2024, 1, 1, 9, 0, 0, 0, jst),
+			Updated: time.Date(2024, 2, 1, 9, 0, 0, 0, jst),
+			Authors: []string{"Alice", "Bob"},
+		},
+	}
+	for name, history := range want {
+		got := files[name]
+		if got == nil || !got.Created.Equal(history.Created) || !got.Updated.Equal(history.Updated) || !reflect.DeepEqual(got.Authors, history.Authors) {
+			t.Errorf("parseGitLog() %s = %+v, want %+v", name, got, history)
+		}
+	}
+	if len(files) != len(want) {
+		t.Errorf("parseGitLog() = %d files, want %d", len(files), len(want))
+	}
+	if err := parseGitLog("\x1einvalid\n\nM\tsrc/a.md\n", files); err == nil {
+		t.Error("parseGitLog() error = nil, want an error for an invalid log")
+	}
+	if err := parseGitLog("\x1e2024-01-01T09:00:00+09:00\x1fAlice\n\nsrc/a.md\n", files); err == nil {
+		t.Error("parseGitLog() error = nil, want an error for a line without a status")
+	}
+}
+
+func TestLoadGitHistories(t *testing.T) {
+	createGitRepository(t)
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+	jst := time.FixedZone("", 9*60*60)
+	head := func() string {
+		out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	writeFiles(t, map[string]string{"src/a.md": "a"})
+	git(t, "add", "-A")
+	git(t, "commit", "--quiet", "--date", "2024-01-01T09:00:00+09:00", "-m", "a")
+	histories, err := LoadGitHistories(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &GitHistory{Created: time.Date(2024, 1, 1, 9, 0, 0, 0, jst), Updated: time.Date(2024, 1, 1, 9, 0, 0, 0, jst), Authors: []string{"Alice"}}
+	if got := histories.Files["src/a.md"]; got == nil || !got.Created.Equal(want.Created) || !got.Updated.Equal(want.Updated) || !reflect.DeepEqual(got.Authors, want.Authors) {
+		t.Errorf("LoadGitHistories() src/a.md = %+v, want %+v", got, want)
+	}
+
+	// Change the cache to see that only the new commit is read in the next load.
+	histories.Files["src/a.md"].Authors = []string{"Carol"}
+	content, err := json.Marshal(histories)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cacheFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, map[string]string{"src/a.md": "a2", "src/b.md": "b"})
+	git(t, "add", "-A")
+	git(t, "commit", "--quiet", "--date", "2024-02-01T09:00:00+09:00", "--author", "Bob <bob@example.com>", "-m", "b")
+
+	histories, err = LoadGitHistories(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wants := map[string]*GitHistory{
+		"src/a.md": {Created: time.Date(2024, 1, 1, 9, 0, 0, 0, jst), Updated: time.Date(2024, 2, 1, 9, 0, 0, 0, jst), Authors: []string{"Carol", "Bob"}},
+		"src/b.md": {Created: time.Date(2024, 2, 1, 9, 0, 0, 0, jst), Updated: time.Date(2024, 2, 1, 9, 0, 0, 0, jst), Authors: []string{"Bob"}},
+	}
+	for name, want := range wants {
+		if got := histories.Files[name]; got == nil || !got.Created.Equal(want.Created) || !got.Updated.Equal(want.Updated) || !reflect.DeepEqual(got.Authors, want.Authors) {
+			t.Errorf("LoadGitHistories() %s = %+v, want %+v", name, got, want)
+		}
+	}
+	if histories.Head != head() {
+		t.Errorf("LoadGitHistories() head = %v, want %v", histories.Head, head())
+	}
+
+	// The cache is updated with the new head.
+	cache, err := loadGitHistoryCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache == nil || cache.Head != head() || len(cache.Files) != 2 {
+		t.Errorf("loadGitHistoryCache() = %+v, want the histories at %v", cache, head())
+	}
+
+	// A renamed file keeps its history, and the documents are matched by their paths in the repository.
+	git(t, "mv", "src/a.md", "src/c.md")
+	git(t, "commit", "--quiet", "--date", "2024-03-01T09:00:00+09:00", "-m", "c")
+	if err := os.Chdir("src"); err != nil {
+		t.Fatal(err)
+	}
+	histories, err = LoadGitHistories(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs("c.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []*Document{{FileName: "c.md"}, {FileName: abs}, {FileName: filepath.Join(t.TempDir(), "c.md")}}
+	histories.Apply(docs)
+	for _, doc := range docs[:2] {
+		if doc.History == nil || !doc.History.Created.Equal(time.Date(2024, 1, 1, 9, 0, 0, 0, jst)) || !doc.History.Updated.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, jst)) {
+			t.Errorf("GitHistories.Apply() %s = %+v, want the history of src/a.md", doc.FileName, doc.History)
+		}
+	}
+	if docs[2].History != nil {
+		t.Errorf("GitHistories.Apply() %s = %+v, want no history outside the repository", docs[2].FileName, docs[2].History)
+	}
+}
+
+func TestHistoryLayout(t *testing.T) {
+	layout := "__CREATED__|__UPDATED__|__AUTHORS__"
+	tests := []struct {
+		name string
+		doc  *Document
+		want string
+	}{
+		{
+			name: "History",
+			doc: &Document{History: &GitHistory{
+				Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Updated: time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC),
+				Authors: []string{"Alice", "Bob & Co"},
+			}},
+			want: `<time datetime="2024-01-01T09:00:00+09:00">2024-01-01</time>|` +
+				`<time datetime="2024-03-02T01:00:00+09:00">2024-03-02</time>|Alice, Bob &amp; Co`,
+		},
+		{
+			name: "Date in the metadata",
+			doc:  &Document{PageMeta: PageMeta{Date: "2024-01-01 00:00"}},
+			want: `<time datetime="2024-01-01T09:00:00+09:00">2024-01-01</time>|` +
+				`<time datetime="2024-01-01T09:00:00+09:00">2024-01-01</time>|`,
+		},
+		{name: "No date", doc: &Document{}, want: "||"},
+		{name: "Index page", doc: nil, want: "||"},
+	}
+	location := time.FixedZone("JST", 9*60*60)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HistoryLayout(layout, tt.doc, location); got != tt.want {
+				t.Errorf("HistoryLayout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -59,8 +59,15 @@ func validateFileNames(paths []string) error {
 	return nil
 }
 
+// isPartial determines if a file or directory is a partial, which is not converted to a page
+// but included by other markdown files. The name of a partial starts with "_".
+func isPartial(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
 // GetMarkDownFileNames searches the specified root directory and all of its subdirectories
 // for files with the ".md" extension and returns a slice containing the paths of all markdown files found.
+// Partials and the files in partial directories are skipped.
 // Parameters:
 // - root: The root directory from which the search will begin.
 func GetMarkDownFileNames(fp IFilePath, root string) ([]string, error) {
@@ -69,7 +76,16 @@ func GetMarkDownFileNames(fp IFilePath, root string) ([]string, error) {
 	if err != nil {
 		return paths, err
 	}
-	if err := fp.WalkDir(root, walkDirFunc); err != nil {
+	skipPartials := func(path string, d fs.DirEntry, err error) error {
+		if err == nil && path != root && isPartial(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return walkDirFunc(path, d, err)
+	}
+	if err := fp.WalkDir(root, skipPartials); err != nil {
 		return paths, errors.WithStack(err)
 	}
 	err = validateFileNames(paths)
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"errors"
 	"io/fs"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -48,6 +50,29 @@ func mfp1(mfp *mock_utils.MockIFilePath) *mock_utils.MockIFilePath {
 	return mfp
 }
 
+func mfp3(mfp *mock_utils.MockIFilePath) *mock_utils.MockIFilePath {
+	mfp.EXPECT().WalkDir(gomock.Any(), gomock.Any()).DoAndReturn(func(root string, fn fs.WalkDirFunc) error {
+		err := fn("/path/to", mockDirEntry{isDir: true, name: "to"}, nil)
+		if err != nil {
+			return err
+		}
+		err = fn("/path/to/markdown1.md", mockDirEntry{isDir: false, name: "markdown1.md"}, nil)
+		if err != nil {
+			return err
+		}
+		err = fn("/path/to/_partial.md", mockDirEntry{isDir: false, name: "_partial.md"}, nil)
+		if err != nil {
+			return err
+		}
+		err = fn("/path/to/_partials", mockDirEntry{isDir: true, name: "_partials"}, nil)
+		if err != filepath.SkipDir {
+			return errors.New("_partials is not skipped")
+		}
+		return nil
+	})
+	return mfp
+}
+
 func mfp2(mfp *mock_utils.MockIFilePath) *mock_utils.MockIFilePath {
 	mfp.EXPECT().WalkDir(gomock.Any(), gomock.Any()).Do(func(root string, fn fs.WalkDirFunc) error {
 		err := fn("/path/to/mark down1.md", mockDirEntry{isDir: false}, nil)
@@ -79,6 +104,15 @@ func TestGetMarkDownFileNames(t *testing.T) {
 			want:    []string{"/path/to/markdown1.md", "/path/to/markdown2.md"},
 			wantErr: false,
 		},
+		{
+			name: "Skip partials",
+			args: args{
+				fp:   mfp3,
+				root: "/path/to",
+			},
+			want:    []string{"/path/to/markdown1.md"},
+			wantErr: false,
+		},
 		{
 			name: "err",
 			args: args{
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	DEFAULT_HIGHLIGHT_STYLE = "github"
+	// NO_HIGHLIGHT is the language that disables highlighting of a code block.
+	NO_HIGHLIGHT = "none"
+)
+
+// HighlightOptions configures syntax highlighting of fenced code blocks.
+type HighlightOptions struct {
+	Enabled bool
+	Style   string // Style is the name of the chroma style used by CreateHighlightCSS.
+	// Languages maps the language of a code block to the name of the chroma lexer.
+	// If it is mapped to "none", the code block is not highlighted.
+	Languages map[string]string
+}
+
+// highlightClassPattern matches the classes that highlighted code blocks use.
+var highlightClassPattern = func() *regexp.Regexp {
+	classes := []string{}
+	for _, class := range chroma.StandardTypes {
+		if class != "" {
+			classes = append(classes, regexp.QuoteMeta(class))
+		}
+	}
+	sort.Strings(classes)
+	return regexp.MustCompile(fmt.Sprintf(`^(%s)$`, strings.Join(classes, "|")))
+}()
+
+// codeLanguagePattern matches the class of the language of a code block.
+var codeLanguagePattern = regexp.MustCompile(`^language-[a-zA-Z0-9_+#.\-]+$`)
+
+// lexer returns the chroma lexer for the language of a code block.
+// It returns nil if highlighting is disabled or no lexer is found.
+func (opts HighlightOptions) lexer(language string) chroma.Lexer {
+	if !opts.Enabled || language == "" {
+		return nil
+	}
+	if name, exists := opts.Languages[strings.ToLower(language)]; exists {
+		language = name
+	}
+	if language == NO_HIGHLIGHT {
+		return nil
+	}
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		return nil
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// ParseHighlightLanguages parses the comma-separated pairs of a language and a chroma lexer such as "sh:bash,txt:none".
+func ParseHighlightLanguages(languages string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, pair := range strings.Split(languages, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		language, name, found := strings.Cut(pair, ":")
+		language = strings.ToLower(strings.TrimSpace(language))
+		name = strings.TrimSpace(name)
+		if !found || language == "" || name == "" {
+			return nil, errors.Errorf("invalid language mapping: %s", pair)
+		}
+		if name != NO_HIGHLIGHT && lexers.Get(name) == nil {
+			return nil, errors.Errorf("unknown language: %s", name)
+		}
+		res[language] = name
+	}
+	return res, nil
+}
+
+// CreateHighlightCSS generates the stylesheet for the classes of highlighted code blocks.
+func CreateHighlightCSS(style string) (string, error) {
+	s, exists := styles.Registry[style]
+	if !exists {
+		return "", errors.Errorf("unknown highlight style: %s", style)
+	}
+	var buf bytes.Buffer
+	err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, s)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return buf.String(), nil
+}
+
+// tokenClass returns the class of a token. A token type without its own class uses the class of its parent type.
+func tokenClass(t chroma.TokenType) string {
+	for t != 0 {
+		if class, exists := chroma.StandardTypes[t]; exists {
+			return class
+		}
+		t = t.Parent()
+	}
+	return chroma.StandardTypes[t]
+}
+
+// writeTokens writes highlighted tokens as spans with classes.
+func writeTokens(w util.BufWriter, tokens []chroma.Token) error {
+	for _, token := range tokens {
+		value := util.EscapeHTML([]byte(token.Value))
+		class := tokenClass(token.Type)
+		if class == "" {
+			if _, err := w.Write(value); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, `<span class="%s">%s</span>`, class, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
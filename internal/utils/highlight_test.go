@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderFencedCodeBlock(t *testing.T) {
+	highlight := HighlightOptions{Enabled: true, Style: DEFAULT_HIGHLIGHT_STYLE, Languages: map[string]string{"txt": NO_HIGHLIGHT}}
+	tests := []struct {
+		name      string
+		highlight HighlightOptions
+		md        string
+		want      string
+	}{
+		{
+			name:      "Highlighted",
+			highlight: highlight,
+			md:        "```go\nfunc main() {}\n```",
+			want:      `<pre class="chroma"><code class="language-go"><span class="kd">func</span> <span class="nf">main</span><span class="p">()</span> <span class="p">{}</span>` + "\n</code></pre>\n",
+		},
+		{
+			name:      "Unknown language",
+			highlight: highlight,
+			md:        "```unknown\n<a>\n```",
+			want:      "<pre><code class=\"language-unknown\">&lt;a&gt;\n</code></pre>\n",
+		},
+		{
+			name:      "No highlight",
+			highlight: highlight,
+			md:        "```txt\nfunc main() {}\n```",
+			want:      "<pre><code class=\"language-txt\">func main() {}\n</code></pre>\n",
+		},
+		{
+			name:      "No language",
+			highlight: highlight,
+			md:        "```\nfunc main() {}\n```",
+			want:      "<pre><code>func main() {}\n</code></pre>\n",
+		},
+		{
+			name:      "Disabled",
+			highlight: HighlightOptions{},
+			md:        "```go\nfunc main() {}\n```",
+			want:      "<pre><code class=\"language-go\">func main() {}\n</code></pre>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureMarkdown(MarkdownOptions{Highlight: tt.highlight})
+			defer ConfigureMarkdown(MarkdownOptions{})
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHighlightLanguages(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages string
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name:      "Valid",
+			languages: "sh:bash, TXT:none",
+			want:      map[string]string{"sh": "bash", "txt": NO_HIGHLIGHT},
+		},
+		{
+			name:      "Empty",
+			languages: "",
+			want:      map[string]string{},
+		},
+		{
+			name:      "Invalid",
+			languages: "sh",
+			wantErr:   true,
+		},
+		{
+			name:      "Unknown lexer",
+			languages: "sh:unknown",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHighlightLanguages(tt.languages)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseHighlightLanguages() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHighlightLanguages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateHighlightCSS(t *testing.T) {
+	got, err := CreateHighlightCSS(DEFAULT_HIGHLIGHT_STYLE)
+	if err != nil {
+		t.Errorf("CreateHighlightCSS() error = %v", err)
+		return
+	}
+	if !strings.Contains(got, ".chroma .kd {") {
+		t.Errorf("CreateHighlightCSS() = %v, want the class kd", got)
+	}
+	if _, err := CreateHighlightCSS("unknown"); err == nil {
+		t.Errorf("CreateHighlightCSS() error = nil, want an error")
+	}
+}
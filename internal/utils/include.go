@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+)
+
+var (
+	// regionStartRe matches the start marker of a region such as "// region: handler".
+	regionStartRe = regexp.MustCompile(`\bregion:\s*([\w.\-]+)`)
+	// regionEndRe matches the end marker of a region such as "// endregion".
+	regionEndRe = regexp.MustCompile(`\bendregion\b`)
+)
+
+// parseLineRange parses a range of lines such as "10-42" or "10".
+func parseLineRange(lines string) (int, int, error) {
+	first, last, isRange := strings.Cut(lines, "-")
+	start, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil || start < 1 {
+		return 0, 0, errors.Errorf("invalid lines: %s", lines)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(last))
+	if err != nil || end < start {
+		return 0, 0, errors.Errorf("invalid lines: %s", lines)
+	}
+	return start, end, nil
+}
+
+// extractLines returns the lines from start to end of content.
+func extractLines(content string, start, end int) (string, bool) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if end > len(lines) {
+		return "", false
+	}
+	return strings.Join(lines[start-1:end], ""), true
+}
+
+// extractRegion returns the lines between "region: name" and the matching "endregion".
+// The lines of the markers of nested regions are removed, and the common indentation is removed.
+func extractRegion(content, name string) (string, bool) {
+	lines := []string{}
+	depth := 0
+	found := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		start := regionStartRe.FindStringSubmatch(line)
+		end := regionEndRe.MatchString(line)
+		if depth == 0 {
+			if start != nil && start[1] == name {
+				depth = 1
+				found = true
+			}
+			continue
+		}
+		switch {
+		case start != nil:
+			depth++
+		case end:
+			depth--
+		default:
+			lines = append(lines, line)
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return dedent(lines), true
+}
+
+// dedent removes the common leading whitespace of the lines except empty lines.
+func dedent(lines []string) string {
+	indent := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first || strings.HasPrefix(indent, lineIndent) {
+			indent = lineIndent
+			first = false
+			continue
+		}
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.TrimPrefix(line, indent))
+	}
+	return b.String()
+}
+
+// readInclude reads the code that a code block includes.
+// The file is relative to dir, and "lines" or "region" selects a part of it.
+func readInclude(dir string, ci codeInfo) (string, string, error) {
+	fileName := filepath.Join(dir, filepath.FromSlash(ci.Attributes["include"]))
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", fileName, errors.Errorf("%s does not exist", fileName)
+	}
+	code := string(content)
+	if lines, exists := ci.Attributes["lines"]; exists {
+		start, end, err := parseLineRange(lines)
+		if err != nil {
+			return "", fileName, err
+		}
+		var ok bool
+		code, ok = extractLines(code, start, end)
+		if !ok {
+			return "", fileName, errors.Errorf("lines %s do not exist in %s", lines, fileName)
+		}
+	}
+	if region, exists := ci.Attributes["region"]; exists {
+		var ok bool
+		code, ok = extractRegion(code, region)
+		if !ok {
+			return "", fileName, errors.Errorf("region %s does not exist in %s", region, fileName)
+		}
+	}
+	if code != "" && !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	return code, fileName, nil
+}
+
+// includeCode reads the code that the fenced code blocks include with the "include" attribute.
+// The code is set to the "code" attribute of the code block, and the included file is added to the inputs of the document.
+func (d *Document) includeCode() error {
+	return ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		block, ok := n.(*ast.FencedCodeBlock)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		line := d.lineAt(-1)
+		info := ""
+		if block.Info != nil {
+			line = d.lineAt(block.Info.Segment.Start)
+			info = string(block.Info.Segment.Value(d.Source))
+		}
+		ci, err := parseCodeInfo(info)
+		if err != nil {
+			return ast.WalkStop, errors.Errorf("%s:%d: %s", d.FileName, line, err)
+		}
+		if _, exists := ci.Attributes["include"]; !exists {
+			return ast.WalkSkipChildren, nil
+		}
+		// パーシャルのコードブロックはパーシャルからの相対パスで読み込む
+		dir := filepath.Dir(d.FileName)
+		if includeDir, exists := block.AttributeString("includeDir"); exists {
+			dir = string(includeDir.([]byte))
+		}
+		code, fileName, err := readInclude(dir, ci)
+		if err != nil {
+			return ast.WalkStop, errors.Errorf("%s:%d: %s", d.FileName, line, err)
+		}
+		block.SetAttributeString("code", []byte(code))
+		d.Inputs = append(d.Inputs, fileName)
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// includedCode returns the code set by includeCode, or the content of the code block.
+func includedCode(node *ast.FencedCodeBlock, source []byte) string {
+	if code, exists := node.AttributeString("code"); exists {
+		return string(code.([]byte))
+	}
+	return codeBlockText(node, source)
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractRegion(t *testing.T) {
+	content := `package main
+
+func main() {
+	// region: handler
+	if true {
+		// region: inner
+		println("a")
+		// endregion
+	}
+	// endregion
+}
+`
+	tests := []struct {
+		name   string
+		region string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "Outer",
+			region: "handler",
+			want:   "if true {\n\tprintln(\"a\")\n}\n",
+			wantOk: true,
+		},
+		{
+			name:   "Inner",
+			region: "inner",
+			want:   "println(\"a\")\n",
+			wantOk: true,
+		},
+		{
+			name:   "Missing",
+			region: "missing",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := extractRegion(content, tt.region)
+			if ok != tt.wantOk {
+				t.Errorf("extractRegion() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("extractRegion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDocuments_Include(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	codeFileName := filepath.Join(dir, "examples", "main.go")
+	if err := os.MkdirAll(filepath.Dir(codeFileName), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sourceDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	code := "package main\n\nfunc main() {\n\t// region: body\n\tprintln(\"a\")\n\t// endregion\n}\n"
+	if err := os.WriteFile(codeFileName, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		md      string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "Lines",
+			md:   "```go include=\"../examples/main.go\" lines=\"3-3\" linenums\n```",
+			want: "<pre><code class=\"language-go\"><span class=\"line\"><span class=\"ln\">3</span><span class=\"cl\">func main() {\n</span></span></code></pre>\n",
+		},
+		{
+			name: "Region",
+			md:   "```go include=\"../examples/main.go\" region=\"body\"\n```",
+			want: "<pre><code class=\"language-go\">println(&quot;a&quot;)\n</code></pre>\n",
+		},
+		{
+			name:    "Missing file",
+			md:      "# A\n\n```go include=\"../examples/missing.go\"\n```",
+			wantErr: "a.md:5: " + filepath.Join(dir, "examples", "missing.go") + " does not exist",
+		},
+		{
+			name:    "Missing region",
+			md:      "```go include=\"../examples/main.go\" region=\"missing\"\n```",
+			wantErr: "a.md:3: region missing does not exist in " + codeFileName,
+		},
+		{
+			name:    "Missing lines",
+			md:      "```go include=\"../examples/main.go\" lines=\"5-10\"\n```",
+			wantErr: "a.md:3: lines 5-10 do not exist in " + codeFileName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(sourceDir, "a.md")
+			if err := os.WriteFile(fileName, []byte("{}\n---\n"+tt.md), 0644); err != nil {
+				t.Fatal(err)
+			}
+			docs, err := LoadDocuments([]string{fileName}, sourceDir)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != filepath.Join(sourceDir, tt.wantErr) {
+					t.Errorf("LoadDocuments() error = %v, want %v", err, filepath.Join(sourceDir, tt.wantErr))
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("LoadDocuments() error = %v", err)
+				return
+			}
+			got, err := docs[0].HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(docs[0].Inputs, []string{fileName, codeFileName}) {
+				t.Errorf("Document.Inputs = %v, want %v", docs[0].Inputs, []string{fileName, codeFileName})
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	DEFAULT_LANG      = "en"
+	INDEX_TRANSLATION = "" // INDEX_TRANSLATION is the translation key of the index pages.
+)
+
+// Language is a language of the site such as "ja" and "en".
+type Language struct {
+	Code string
+	// Name is shown in the language switcher. It is the code if it is not configured.
+	Name string
+}
+
+// ParseLanguages parses a comma separated list of languages such as "ja:日本語,en:English".
+// The name after ":" is optional.
+func ParseLanguages(value string) []Language {
+	languages := []Language{}
+	for _, item := range strings.Split(value, ",") {
+		code, name, _ := strings.Cut(item, ":")
+		code, name = strings.TrimSpace(code), strings.TrimSpace(name)
+		if code == "" {
+			continue
+		}
+		if name == "" {
+			name = code
+		}
+		languages = append(languages, Language{Code: code, Name: name})
+	}
+	return languages
+}
+
+// defaultLanguage returns the code of the first language, or "" if no language is configured.
+func defaultLanguage() string {
+	if len(markdownOptions.Languages) == 0 {
+		return ""
+	}
+	return markdownOptions.Languages[0].Code
+}
+
+// isLanguage determines if a code is one of the languages of the site.
+func isLanguage(code string) bool {
+	for _, l := range markdownOptions.Languages {
+		if l.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// LanguagePrefix returns the prefix of the URLs of the pages in a language such as "/en".
+// The pages in the default language do not have the prefix.
+func LanguagePrefix(lang string) string {
+	if lang == "" || lang == defaultLanguage() {
+		return ""
+	}
+	return "/" + lang
+}
+
+// translationPath returns the language of a markdown file, and the directory and the name of the page without the language.
+// The language is detected from the first directory under sourceDir such as "src/en/page.md",
+// or from the extension before ".md" such as "src/page.en.md". Otherwise the page is in the default language.
+func translationPath(markDownFileName, sourceDir string) (string, string, string) {
+	dir, name := GetDirAndName(markDownFileName)
+	rel := strings.Trim(strings.TrimPrefix(filepath.ToSlash(dir), filepath.ToSlash(sourceDir)), "/")
+	first, rest, _ := strings.Cut(rel, "/")
+	if isLanguage(first) {
+		return first, filepath.Join(sourceDir, rest), name
+	}
+	if ext := filepath.Ext(name); ext != "" && isLanguage(ext[1:]) {
+		return ext[1:], dir, strings.TrimSuffix(name, ext)
+	}
+	return defaultLanguage(), dir, name
+}
+
+// Language returns the language of the page.
+func (d *Document) Language(sourceDir string) string {
+	lang, _, _ := translationPath(d.FileName, sourceDir)
+	return lang
+}
+
+// TranslationKey returns the key that the page shares with its translations.
+// It is translationKey in the metadata, or the path of the page under sourceDir without the language such as "guide/page".
+func (d *Document) TranslationKey(sourceDir string) string {
+	if d.PageMeta.TranslationKey != "" {
+		return d.PageMeta.TranslationKey
+	}
+	_, dir, name := translationPath(d.FileName, sourceDir)
+	rel := strings.Trim(strings.TrimPrefix(filepath.ToSlash(dir), filepath.ToSlash(sourceDir)), "/")
+	return strings.TrimPrefix(rel+"/"+name, "/")
+}
+
+// HTMLFileName returns the name of the HTML file generated from the document.
+func (d *Document) HTMLFileName(sourceDir, outputDir string) string {
+	lang, dir, name := translationPath(d.FileName, sourceDir)
+	return filepath.Join(CreateHTMLFileDir(dir, sourceDir, filepath.Join(outputDir, LanguagePrefix(lang))), name+".html")
+}
+
+// GroupDocumentsByLanguage groups the documents by their languages.
+func GroupDocumentsByLanguage(docs []*Document, sourceDir string) map[string][]*Document {
+	groups := map[string][]*Document{}
+	for _, doc := range docs {
+		lang := doc.Language(sourceDir)
+		groups[lang] = append(groups[lang], doc)
+	}
+	return groups
+}
+
+// Translations holds the URLs of the translations of the pages.
+type Translations struct {
+	languages []Language
+	// urls maps the translation keys to the URLs of the pages in each language.
+	urls map[string]map[string]string
+}
+
+// NewTranslations collects the translations of the documents.
+// The index page of each language is registered with INDEX_TRANSLATION.
+func NewTranslations(docs []*Document, sourceDir, baseURL string) *Translations {
+	t := &Translations{languages: markdownOptions.Languages, urls: map[string]map[string]string{INDEX_TRANSLATION: {}}}
+	for _, l := range t.languages {
+		t.urls[INDEX_TRANSLATION][l.Code] = baseURL + LanguagePrefix(l.Code) + "/"
+	}
+	for _, doc := range docs {
+		key := doc.TranslationKey(sourceDir)
+		if t.urls[key] == nil {
+			t.urls[key] = map[string]string{}
+		}
+		t.urls[key][doc.Language(sourceDir)] = doc.URL(sourceDir, baseURL)
+	}
+	return t
+}
+
+// Alternates returns the link elements to the translations of a page with hreflang.
+// It returns "" if the page has no translation.
+func (t *Translations) Alternates(key string) string {
+	urls := t.urls[key]
+	if len(urls) < 2 {
+		return ""
+	}
+	var links strings.Builder
+	for _, l := range t.languages {
+		if url, exists := urls[l.Code]; exists {
+			links.WriteString(fmt.Sprintf(`<link rel="alternate" hreflang="%s" href="%s" />`, html.EscapeString(l.Code), html.EscapeString(url)))
+		}
+	}
+	if url, exists := urls[defaultLanguage()]; exists {
+		links.WriteString(fmt.Sprintf(`<link rel="alternate" hreflang="x-default" href="%s" />`, html.EscapeString(url)))
+	}
+	return links.String()
+}
+
+// Switcher returns the navigation that links to the page in each language.
+// If the page is not translated into a language, it links to the index page of the language.
+// It returns "" if the site has only one language.
+func (t *Translations) Switcher(key, lang string) string {
+	if len(t.languages) < 2 {
+		return ""
+	}
+	var nav strings.Builder
+	nav.WriteString(`<nav class="language-switcher">`)
+	for _, l := range t.languages {
+		url, exists := t.urls[key][l.Code]
+		if !exists {
+			url = t.urls[INDEX_TRANSLATION][l.Code]
+		}
+		current := ""
+		if l.Code == lang {
+			current = ` class="current" aria-current="page"`
+		}
+		nav.WriteString(fmt.Sprintf(`<a href="%s" hreflang="%s" lang="%s"%s>%s</a>`,
+			html.EscapeString(url), html.EscapeString(l.Code), html.EscapeString(l.Code), current, html.EscapeString(l.Name)))
+	}
+	nav.WriteString("</nav>")
+	return nav.String()
+}
+
+// LocalizeLayout replaces __LANG__, __ALTERNATES__ and __LANGUAGE_SWITCHER__ in a layout for a page in a language.
+// If no language is configured, __LANG__ is replaced with DEFAULT_LANG.
+func (t *Translations) LocalizeLayout(layout, key, lang string) string {
+	code := lang
+	if code == "" {
+		code = DEFAULT_LANG
+	}
+	layout = strings.ReplaceAll(layout, LANG, html.EscapeString(code))
+	layout = strings.ReplaceAll(layout, ALTERNATES, t.Alternates(key))
+	return strings.ReplaceAll(layout, SWITCHER, t.Switcher(key, lang))
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLanguages(t *testing.T) {
+	got := ParseLanguages(" ja:日本語, en ,")
+	want := []Language{{Code: "ja", Name: "日本語"}, {Code: "en", Name: "en"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLanguages() = %v, want %v", got, want)
+	}
+}
+
+func TestDocument_Translation(t *testing.T) {
+	tests := []struct {
+		fileName     string
+		lang         string
+		key          string
+		url          string
+		htmlFileName string
+	}{
+		{fileName: "src/guide/page.md", lang: "ja", key: "guide/page", url: "https://example.com/guide/page.html", htmlFileName: "out/guide/page.html"},
+		{fileName: "src/guide/page.en.md", lang: "en", key: "guide/page", url: "https://example.com/en/guide/page.html", htmlFileName: "out/en/guide/page.html"},
+		{fileName: "src/en/guide/page.md", lang: "en", key: "guide/page", url: "https://example.com/en/guide/page.html", htmlFileName: "out/en/guide/page.html"},
+		{fileName: "src/ja/page.md", lang: "ja", key: "page", url: "https://example.com/page.html", htmlFileName: "out/page.html"},
+		{fileName: "src/page.fr.md", lang: "ja", key: "page.fr", url: "https://example.com/page.fr.html", htmlFileName: "out/page.fr.html"},
+	}
+	ConfigureMarkdown(MarkdownOptions{Languages: ParseLanguages("ja,en")})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			doc := &Document{FileName: tt.fileName}
+			if got := doc.Language("src"); got != tt.lang {
+				t.Errorf("Document.Language() = %v, want %v", got, tt.lang)
+			}
+			if got := doc.TranslationKey("src"); got != tt.key {
+				t.Errorf("Document.TranslationKey() = %v, want %v", got, tt.key)
+			}
+			if got := doc.URL("src", "https://example.com"); got != tt.url {
+				t.Errorf("Document.URL() = %v, want %v", got, tt.url)
+			}
+			if got := doc.HTMLFileName("src", "out"); got != tt.htmlFileName {
+				t.Errorf("Document.HTMLFileName() = %v, want %v", got, tt.htmlFileName)
+			}
+		})
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	ConfigureMarkdown(MarkdownOptions{Languages: ParseLanguages("ja:日本語,en:English")})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	docs := []*Document{{FileName: "src/a.md"}, {FileName: "src/a.en.md"}, {FileName: "src/b.md"}}
+	translations := NewTranslations(docs, "src", "https://example.com")
+
+	want := `<link rel="alternate" hreflang="ja" href="https://example.com/a.html" />` +
+		`<link rel="alternate" hreflang="en" href="https://example.com/en/a.html" />` +
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/a.html" />`
+	if got := translations.Alternates("a"); got != want {
+		t.Errorf("Translations.Alternates() = %v, want %v", got, want)
+	}
+	if got := translations.Alternates("b"); got != "" {
+		t.Errorf("Translations.Alternates() = %v, want no alternates", got)
+	}
+
+	want = `<nav class="language-switcher"><a href="https://example.com/b.html" hreflang="ja" lang="ja" class="current" aria-current="page">日本語</a>` +
+		`<a href="https://example.com/en/" hreflang="en" lang="en">English</a></nav>`
+	if got := translations.Switcher("b", "ja"); got != want {
+		t.Errorf("Translations.Switcher() = %v, want %v", got, want)
+	}
+
+	got := translations.LocalizeLayout(`<html lang="__LANG__">__ALTERNATES__`, "a", "en")
+	if !strings.HasPrefix(got, `<html lang="en"><link rel="alternate" hreflang="ja"`) {
+		t.Errorf("Translations.LocalizeLayout() = %v", got)
+	}
+}
+
+func TestTranslations_SingleLanguage(t *testing.T) {
+	translations := NewTranslations([]*Document{{FileName: "src/a.md"}}, "src", "")
+	if got := translations.LocalizeLayout(`<html lang="__LANG__">__ALTERNATES____LANGUAGE_SWITCHER__`, "a", ""); got != `<html lang="en">` {
+		t.Errorf("Translations.LocalizeLayout() = %v", got)
+	}
+}
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+)
+
+// htmlLink represents a URL referenced by an element in an HTML file.
+type htmlLink struct {
+	Line int
+	URL  string
+}
+
+// htmlFile holds the links and the IDs of the elements in an HTML file.
+type htmlFile struct {
+	Links []htmlLink
+	IDs   map[string]bool
+}
+
+// linkAttributes maps elements to the attributes that reference other files.
+var linkAttributes = map[string]string{
+	"a":      "href",
+	"link":   "href",
+	"img":    "src",
+	"script": "src",
+	"audio":  "src",
+	"video":  "src",
+	"source": "src",
+	"iframe": "src",
+}
+
+// parseHTMLFile collects the links and the IDs of the elements in HTML content.
+// The line number of each link is the line where its element starts.
+func parseHTMLFile(content []byte) *htmlFile {
+	file := &htmlFile{IDs: map[string]bool{}}
+	z := html.NewTokenizer(bytes.NewReader(content))
+	line := 1
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return file
+		}
+		raw := z.Raw()
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			token := z.Token()
+			for _, attr := range token.Attr {
+				switch {
+				case attr.Key == "id" || (token.Data == "a" && attr.Key == "name"):
+					file.IDs[attr.Val] = true
+				case linkAttributes[token.Data] == attr.Key:
+					file.Links = append(file.Links, htmlLink{Line: line, URL: attr.Val})
+				}
+			}
+		}
+		line += bytes.Count(raw, []byte("\n"))
+	}
+}
+
+// LinkChecker verifies the internal links, fragments and assets of the generated HTML files.
+type LinkChecker struct {
+	outputDir string
+	base      *url.URL
+	files     map[string]*htmlFile
+}
+
+// NewLinkChecker creates a LinkChecker for the HTML files in outputDir that are published at baseURL.
+func NewLinkChecker(outputDir, baseURL string) (*LinkChecker, error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &LinkChecker{outputDir: outputDir, base: base, files: map[string]*htmlFile{}}, nil
+}
+
+// load parses an HTML file. The parsed file is cached.
+func (c *LinkChecker) load(fileName string) (*htmlFile, error) {
+	if file, exists := c.files[fileName]; exists {
+		return file, nil
+	}
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	file := parseHTMLFile(content)
+	c.files[fileName] = file
+	return file, nil
+}
+
+// sitePath converts a file in the output directory to its path on the site, such as "/mujidoc/go/index.html".
+func (c *LinkChecker) sitePath(fileName string) (string, error) {
+	rel, err := filepath.Rel(c.outputDir, fileName)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path.Join("/", c.base.Path, filepath.ToSlash(rel)), nil
+}
+
+// resolve converts a link in the page at pagePath to a file in the output directory and a fragment.
+// It returns false if the link does not point to the site.
+func (c *LinkChecker) resolve(pagePath, link string) (string, string, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", false
+	}
+	if u.Scheme != "" || u.Host != "" {
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host != c.base.Host {
+			return "", "", false
+		}
+	}
+	target := u.Path
+	switch {
+	case u.Scheme == "" && u.Host == "" && target == "":
+		target = pagePath
+	case !strings.HasPrefix(target, "/"):
+		target = path.Join(path.Dir(pagePath), target)
+	}
+	basePath := strings.TrimSuffix(c.base.Path, "/")
+	if target != basePath && !strings.HasPrefix(target, basePath+"/") {
+		return "", "", false
+	}
+	fileName := filepath.Join(c.outputDir, filepath.FromSlash(strings.TrimPrefix(target, basePath)))
+	if IsDirExists(fileName) {
+		fileName = filepath.Join(fileName, "index.html")
+	}
+	return fileName, u.Fragment, true
+}
+
+// checkFile verifies the links in an HTML file.
+func (c *LinkChecker) checkFile(fileName string) ([]Diagnostic, error) {
+	file, err := c.load(fileName)
+	if err != nil {
+		return nil, err
+	}
+	pagePath, err := c.sitePath(fileName)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := []Diagnostic{}
+	for _, link := range file.Links {
+		target, fragment, internal := c.resolve(pagePath, link.URL)
+		if !internal {
+			continue
+		}
+		if _, err := os.Stat(target); err != nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				File:    fileName,
+				Line:    link.Line,
+				Message: fmt.Sprintf("%s: %s does not exist", link.URL, target),
+			})
+			continue
+		}
+		if fragment == "" || filepath.Ext(target) != ".html" {
+			continue
+		}
+		targetFile, err := c.load(target)
+		if err != nil {
+			return nil, err
+		}
+		if !targetFile.IDs[fragment] {
+			diagnostics = append(diagnostics, Diagnostic{
+				File:    fileName,
+				Line:    link.Line,
+				Message: fmt.Sprintf("%s: #%s does not exist in %s", link.URL, fragment, target),
+			})
+		}
+	}
+	return diagnostics, nil
+}
+
+// Check verifies every HTML file in the output directory.
+// It reports internal links to files that do not exist, fragments that do not match any ID in the target files,
+// and assets such as images that do not exist.
+func (c *LinkChecker) Check() ([]Diagnostic, error) {
+	diagnostics := []Diagnostic{}
+	err := filepath.WalkDir(c.outputDir, func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() || filepath.Ext(fileName) != ".html" {
+			return nil
+		}
+		ds, err := c.checkFile(fileName)
+		if err != nil {
+			return err
+		}
+		diagnostics = append(diagnostics, ds...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	SortDiagnostics(diagnostics)
+	return diagnostics, nil
+}
+
+// CheckLinks verifies the links of the HTML files in outputDir that are published at baseURL.
+func CheckLinks(outputDir, baseURL string) ([]Diagnostic, error) {
+	checker, err := NewLinkChecker(outputDir, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return checker.Check()
+}
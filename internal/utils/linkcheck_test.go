@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	outputDir := t.TempDir()
+	files := map[string]string{
+		"index.html": `<html>
+<body>
+<a href="https://example.com/site/go/a.html#Section">a</a>
+<a href="go/a.html#Missing">a</a>
+<a href="https://example.com/site/missing.html">missing</a>
+<a href="https://other.example.com/missing.html">other</a>
+<a href="/outside.html">outside</a>
+<a href="https://example.com/site/go">dir</a>
+</body>
+</html>`,
+		"go/a.html": `<html>
+<body>
+<h2 id="Section"><a href="#Section">Section</a></h2>
+<img src="../images/a.png">
+<img src="../images/missing.png">
+<a href="#Nothing">nothing</a>
+</body>
+</html>`,
+		"go/index.html":  `<html></html>`,
+		"images/a.png":   "",
+		"app.css":        "",
+		"nested/x.html":  `<link rel="stylesheet" href="/site/app.css?v=1">`,
+		"nested/y.html":  `<a href="x.html">x</a>`,
+		"nested/z.txt":   `<a href="missing.html">ignored</a>`,
+		"nested/w/.keep": "",
+	}
+	for name, content := range files {
+		fileName := filepath.Join(outputDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := CheckLinks(outputDir, "https://example.com/site")
+	if err != nil {
+		t.Errorf("CheckLinks() error = %v", err)
+		return
+	}
+	want := []Diagnostic{
+		{
+			File:    filepath.Join(outputDir, "go/a.html"),
+			Line:    5,
+			Message: "../images/missing.png: " + filepath.Join(outputDir, "images/missing.png") + " does not exist",
+		},
+		{
+			File:    filepath.Join(outputDir, "go/a.html"),
+			Line:    6,
+			Message: "#Nothing: #Nothing does not exist in " + filepath.Join(outputDir, "go/a.html"),
+		},
+		{
+			File:    filepath.Join(outputDir, "index.html"),
+			Line:    4,
+			Message: "go/a.html#Missing: #Missing does not exist in " + filepath.Join(outputDir, "go/a.html"),
+		},
+		{
+			File:    filepath.Join(outputDir, "index.html"),
+			Line:    5,
+			Message: "https://example.com/site/missing.html: " + filepath.Join(outputDir, "missing.html") + " does not exist",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckLinks() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+)
+
+// splitFragment splits a link destination into the path and the rest, which is the query and the fragment.
+func splitFragment(destination string) (string, string) {
+	i := strings.IndexAny(destination, "?#")
+	if i == -1 {
+		return destination, ""
+	}
+	return destination[:i], destination[i:]
+}
+
+// isRelativeMarkdownLink determines if a link destination is a relative path to a markdown file.
+func isRelativeMarkdownLink(destination string) bool {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return false
+	}
+	path, _ := splitFragment(destination)
+	return path != "" && !strings.HasPrefix(path, "/") && strings.HasSuffix(path, ".md")
+}
+
+// resolveMarkdownLink resolves a relative link to a markdown file to the path of the file.
+// The path is relative to the markdown file that contains the link.
+func resolveMarkdownLink(markDownFileName, destination string) (string, string, error) {
+	path, rest := splitFragment(destination)
+	path, err := url.PathUnescape(path)
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(markDownFileName), filepath.FromSlash(path))), rest, nil
+}
+
+// ResolveLinks rewrites the relative links to markdown files into the URLs of the pages generated from them.
+// The query and fragment of a link are kept.
+// If a linked markdown file does not exist, ResolveLinks returns an error when strict is true.
+// Otherwise it adds a diagnostic to the document and leaves the link as it is.
+func ResolveLinks(docs []*Document, sourceDir, baseURL string, strict bool) error {
+	urls := map[string]string{}
+	for _, doc := range docs {
+		urls[filepath.Clean(doc.FileName)] = doc.URL(sourceDir, baseURL)
+	}
+
+	for _, doc := range docs {
+		err := ast.Walk(doc.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			link, ok := n.(*ast.Link)
+			if !ok || !entering || !isRelativeMarkdownLink(string(link.Destination)) {
+				return ast.WalkContinue, nil
+			}
+			path, rest, err := resolveMarkdownLink(doc.FileName, string(link.Destination))
+			if err != nil {
+				return ast.WalkStop, err
+			}
+			u, exists := urls[path]
+			if !exists {
+				diagnostic := Diagnostic{
+					File:    doc.FileName,
+					Line:    doc.Line(link),
+					Message: fmt.Sprintf("%s does not exist", link.Destination),
+				}
+				if strict {
+					return ast.WalkStop, errors.New(diagnostic.String())
+				}
+				doc.Diagnostics = append(doc.Diagnostics, diagnostic)
+				return ast.WalkContinue, nil
+			}
+			link.Destination = []byte(u + rest)
+			return ast.WalkContinue, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveLinks(t *testing.T) {
+	newDocs := func() []*Document {
+		a, _ := ParseDocument("src/go/a.md", []byte("{}\n---\n# A\n\n[b](../b.md#example) [c](c.md?x=1) [web](https://example.com/d.md) [top](/e.md)"))
+		b, _ := ParseDocument("src/b.md", []byte("{}\n---\n# B\n\n[a](go/a.md)"))
+		c, _ := ParseDocument("src/go/c.md", []byte("{}\n---\n# C\n\n\n[missing](missing.md)"))
+		return []*Document{a, b, c}
+	}
+	type args struct {
+		strict bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "Not strict",
+			args: args{strict: false},
+			want: [][]string{
+				{"https://example.com/b.html#example", "https://example.com/go/c.html?x=1", "https://example.com/d.md", "/e.md"},
+				{"https://example.com/go/a.html"},
+				{"missing.md"},
+			},
+		},
+		{
+			name:    "Strict",
+			args:    args{strict: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := newDocs()
+			err := ResolveLinks(docs, "src", "https://example.com", tt.args.strict)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveLinks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if err.Error() != "src/go/c.md:6: missing.md does not exist" {
+					t.Errorf("ResolveLinks() error = %v", err)
+				}
+				return
+			}
+			want := []Diagnostic{{File: "src/go/c.md", Line: 6, Message: "missing.md does not exist"}}
+			if !reflect.DeepEqual(docs[2].Diagnostics, want) {
+				t.Errorf("Document.Diagnostics = %v, want %v", docs[2].Diagnostics, want)
+			}
+			for i, doc := range docs {
+				html, err := doc.HTML()
+				if err != nil {
+					t.Errorf("Document.HTML() error = %v", err)
+					return
+				}
+				for _, want := range tt.want[i] {
+					if !strings.Contains(html, `href="`+want+`"`) {
+						t.Errorf("Document.HTML() = %v, want href %v", html, want)
+					}
+				}
+			}
+		})
+	}
+}
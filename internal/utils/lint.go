@@ -0,0 +1,484 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+)
+
+const (
+	LINT_STYLE            = "style"
+	LINT_WIDTH            = "width"
+	LINT_SENTENCE_LENGTH  = "sentence-length"
+	LINT_DOUBLED_PARTICLE = "doubled-particle"
+	LINT_SPACING          = "spacing"
+
+	DEFAULT_LINT_MAX_SENTENCE_LENGTH = 100
+
+	LINT_SPACING_ALWAYS = "always"
+	LINT_SPACING_NEVER  = "never"
+)
+
+// LintRules holds the names of the lint rules.
+var LintRules = []string{LINT_STYLE, LINT_WIDTH, LINT_SENTENCE_LENGTH, LINT_DOUBLED_PARTICLE, LINT_SPACING}
+
+// LintOptions configures the linter of Japanese text.
+type LintOptions struct {
+	// Disabled holds the names of the rules that are not run.
+	Disabled map[string]bool
+	// MaxSentenceLength is the maximum number of characters in a sentence. If it is 0 or less, the length is not checked.
+	MaxSentenceLength int
+	// Spacing is "always" if Japanese and Latin words must be separated by a space, and "never" if they must not.
+	// Otherwise the spaces between them are not checked.
+	Spacing string
+}
+
+func (o LintOptions) enabled(rule string) bool {
+	return !o.Disabled[rule]
+}
+
+// ParseLintRules parses a comma separated list of the names of lint rules such as "width,sentence-length".
+func ParseLintRules(value string) (map[string]bool, error) {
+	rules := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(LintRules, name) {
+			return nil, errors.Errorf("unknown lint rule: %s", name)
+		}
+		rules[name] = true
+	}
+	return rules, nil
+}
+
+const (
+	// lintLineBreak is put in a lintText for a line break.
+	lintLineBreak = '\n'
+	// lintObject is put in a lintText for an inline whose text is not prose, such as code and a formula.
+	lintObject = '\uFFFC'
+)
+
+// lintText is the text of a block such as a paragraph and a heading.
+type lintText struct {
+	runes []rune
+	// offsets holds the offset in the markdown text of each character, or -1 if the character is not in the markdown text.
+	offsets []int
+}
+
+func (t *lintText) append(r rune, offset int) {
+	t.runes = append(t.runes, r)
+	t.offsets = append(t.offsets, offset)
+}
+
+func (t *lintText) appendString(s string, offset int) {
+	for i, r := range s {
+		if offset < 0 {
+			t.append(r, -1)
+		} else {
+			t.append(r, offset+i)
+		}
+	}
+}
+
+// offset returns the offset in the markdown text of the character at i, or of the nearest character that has it.
+func (t *lintText) offset(i int) int {
+	for j := i; j >= 0; j-- {
+		if t.offsets[j] >= 0 {
+			return t.offsets[j]
+		}
+	}
+	for j := i + 1; j < len(t.offsets); j++ {
+		if t.offsets[j] >= 0 {
+			return t.offsets[j]
+		}
+	}
+	return -1
+}
+
+// firstOffset returns the offset of the first text in a node, or -1 if it has no text.
+func firstOffset(node ast.Node) int {
+	offset := -1
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering {
+			offset = t.Segment.Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return offset
+}
+
+// appendLintText appends the text of the inlines in a node to t.
+func (d *Document) appendLintText(t *lintText, node ast.Node) {
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			t.appendString(string(c.Segment.Value(d.Source)), c.Segment.Start)
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				t.append(lintLineBreak, -1)
+			}
+		case *ast.String:
+			t.appendString(string(c.Value), -1)
+		case *ast.CodeSpan, *MathInline, *ast.RawHTML, *ast.AutoLink, *ast.Image:
+			t.append(lintObject, firstOffset(c))
+		default:
+			d.appendLintText(t, c)
+		}
+	}
+}
+
+// lintTexts returns the texts of the blocks that have inlines. Code blocks, formulas and HTML blocks are skipped.
+func (d *Document) lintTexts() []*lintText {
+	texts := []*lintText{}
+	_ = ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock || n.FirstChild() == nil || n.FirstChild().Type() != ast.TypeInline {
+			return ast.WalkContinue, nil
+		}
+		t := &lintText{}
+		d.appendLintText(t, n)
+		texts = append(texts, t)
+		return ast.WalkSkipChildren, nil
+	})
+	return texts
+}
+
+// isJapanese determines if a character is kanji, hiragana or katakana.
+func isJapanese(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー' || r == '々' || r == '〆'
+}
+
+// isLatin determines if a character is a half-width alphanumeric.
+func isLatin(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// isFullWidthAlphanumeric determines if a character is a full-width alphanumeric such as Ａ and １.
+func isFullWidthAlphanumeric(r rune) bool {
+	return 'Ａ' <= r && r <= 'Ｚ' || 'ａ' <= r && r <= 'ｚ' || '０' <= r && r <= '９'
+}
+
+const (
+	sentenceTerminators = "。．！？!?"
+	closingBrackets     = "」』）)】〉》”’"
+)
+
+// lintSentence is a sentence in a lintText. start and end are the indexes of its first character and the character after it.
+type lintSentence struct {
+	start, end int
+}
+
+// sentences splits the text into sentences.
+// The terminators and the closing brackets that follow a terminator such as "！？" and "。」" belong to the sentence.
+func (t *lintText) sentences() []lintSentence {
+	sentences := []lintSentence{}
+	start := 0
+	for i := 0; i < len(t.runes); i++ {
+		if !strings.ContainsRune(sentenceTerminators, t.runes[i]) {
+			continue
+		}
+		for i+1 < len(t.runes) && strings.ContainsRune(sentenceTerminators+closingBrackets, t.runes[i+1]) {
+			i++
+		}
+		sentences = append(sentences, lintSentence{start, i + 1})
+		start = i + 1
+	}
+	if start < len(t.runes) {
+		sentences = append(sentences, lintSentence{start, len(t.runes)})
+	}
+	return sentences
+}
+
+// trimSentence returns the sentence without the spaces and line breaks around it.
+func (t *lintText) trimSentence(s lintSentence) lintSentence {
+	for s.start < s.end && (unicode.IsSpace(t.runes[s.start]) || t.runes[s.start] == '　') {
+		s.start++
+	}
+	for s.end > s.start && (unicode.IsSpace(t.runes[s.end-1]) || t.runes[s.end-1] == '　') {
+		s.end--
+	}
+	return s
+}
+
+const (
+	politeStyle = "です・ます"
+	plainStyle  = "だ・である"
+)
+
+var (
+	politeEndings = []string{"です", "ます", "でした", "ました", "ません", "でしょう", "ましょう", "ですか", "ますか", "ですね", "ますね", "ください"}
+	plainEndings  = []string{"だ", "である", "た", "ない", "だろう", "であろう"}
+)
+
+// sentenceStyle returns the style of the end of a sentence, or "" if it is unknown.
+// A quotation such as 「…だ。」 is not classified because it may be written in the other style.
+func sentenceStyle(sentence string) string {
+	body := strings.TrimRight(sentence, sentenceTerminators+closingBrackets)
+	if strings.ContainsAny(sentence[len(body):], closingBrackets) {
+		return ""
+	}
+	for _, ending := range politeEndings {
+		if strings.HasSuffix(body, ending) {
+			return politeStyle
+		}
+	}
+	for _, ending := range plainEndings {
+		if strings.HasSuffix(body, ending) {
+			return plainStyle
+		}
+	}
+	return ""
+}
+
+// lintLocation is the location of a character in the texts of a document.
+type lintLocation struct {
+	text  *lintText
+	index int
+}
+
+// lintStyle reports the sentences that are not written in the main style of the page.
+// The main style is the style of most sentences. If the numbers are the same, it is the style of the first sentence.
+func (d *Document) lintStyle(texts []*lintText) []Diagnostic {
+	styles := map[string][]lintLocation{}
+	first := ""
+	for _, t := range texts {
+		for _, s := range t.sentences() {
+			s = t.trimSentence(s)
+			style := sentenceStyle(strings.ReplaceAll(string(t.runes[s.start:s.end]), string(lintLineBreak), ""))
+			if style == "" {
+				continue
+			}
+			if first == "" {
+				first = style
+			}
+			styles[style] = append(styles[style], lintLocation{t, s.start})
+		}
+	}
+	main, other := first, plainStyle
+	if first == plainStyle {
+		other = politeStyle
+	}
+	if len(styles[other]) > len(styles[main]) {
+		main, other = other, main
+	}
+	diagnostics := []Diagnostic{}
+	for _, l := range styles[other] {
+		diagnostics = append(diagnostics, Diagnostic{
+			File:    d.FileName,
+			Line:    d.lineAt(l.text.offset(l.index)),
+			Message: fmt.Sprintf("sentence in %q style in a page written in %q style", other, main),
+			Rule:    LINT_STYLE,
+		})
+	}
+	return diagnostics
+}
+
+// widthCounterparts maps the Japanese commas and periods to the other forms.
+var widthCounterparts = map[rune]rune{'、': '，', '。': '．', '，': '、', '．': '。'}
+
+// lintWidth reports the alphanumerics and punctuations whose widths are inconsistent in the page.
+// If both full-width and half-width alphanumerics are used, the less used ones are reported.
+// It also reports 、。 mixed with ，． and half-width punctuations just after Japanese text.
+func (d *Document) lintWidth(texts []*lintText) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	report := func(t *lintText, i int, message string) {
+		diagnostics = append(diagnostics, Diagnostic{File: d.FileName, Line: d.lineAt(t.offset(i)), Message: message, Rule: LINT_WIDTH})
+	}
+	fullWidth, halfWidth := []lintLocation{}, []lintLocation{}
+	japanesePunctuations, otherPunctuations := []lintLocation{}, []lintLocation{}
+	for _, t := range texts {
+		for i, r := range t.runes {
+			switch {
+			case isFullWidthAlphanumeric(r) && (i == 0 || !isFullWidthAlphanumeric(t.runes[i-1])):
+				fullWidth = append(fullWidth, lintLocation{t, i})
+			case isLatin(r) && (i == 0 || !isLatin(t.runes[i-1])):
+				halfWidth = append(halfWidth, lintLocation{t, i})
+			case r == '、' || r == '。':
+				japanesePunctuations = append(japanesePunctuations, lintLocation{t, i})
+			case r == '，' || r == '．':
+				otherPunctuations = append(otherPunctuations, lintLocation{t, i})
+			case strings.ContainsRune(",.!?:;", r) && i > 0 && isJapanese(t.runes[i-1]):
+				report(t, i, fmt.Sprintf("half-width %q after Japanese text", string(r)))
+			}
+		}
+	}
+	if len(fullWidth) > 0 && len(halfWidth) > 0 {
+		minor, width, inRun, other := fullWidth, "full-width", isFullWidthAlphanumeric, "half-width"
+		if len(fullWidth) > len(halfWidth) {
+			minor, width, inRun, other = halfWidth, "half-width", isLatin, "full-width"
+		}
+		for _, l := range minor {
+			end := l.index
+			for end < len(l.text.runes) && inRun(l.text.runes[end]) {
+				end++
+			}
+			report(l.text, l.index, fmt.Sprintf("%s alphanumerics %q in a page that uses %s ones", width, string(l.text.runes[l.index:end]), other))
+		}
+	}
+	if len(japanesePunctuations) > 0 && len(otherPunctuations) > 0 {
+		minor := otherPunctuations
+		if len(otherPunctuations) > len(japanesePunctuations) {
+			minor = japanesePunctuations
+		}
+		for _, l := range minor {
+			r := l.text.runes[l.index]
+			report(l.text, l.index, fmt.Sprintf("%q is mixed with %q", string(r), string(widthCounterparts[r])))
+		}
+	}
+	return diagnostics
+}
+
+// lintSentenceLength reports the sentences in Japanese that are longer than MaxSentenceLength.
+// Line breaks in a sentence are not counted.
+func (d *Document) lintSentenceLength(texts []*lintText, max int) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, t := range texts {
+		for _, s := range t.sentences() {
+			s = t.trimSentence(s)
+			length, japanese := 0, false
+			for _, r := range t.runes[s.start:s.end] {
+				if r != lintLineBreak {
+					length++
+				}
+				japanese = japanese || isJapanese(r)
+			}
+			if japanese && length > max {
+				diagnostics = append(diagnostics, Diagnostic{
+					File:    d.FileName,
+					Line:    d.lineAt(t.offset(s.start)),
+					Message: fmt.Sprintf("sentence is %d characters long (max %d)", length, max),
+					Rule:    LINT_SENTENCE_LENGTH,
+				})
+			}
+		}
+	}
+	return diagnostics
+}
+
+// doubledParticles holds the particles that are reported when they are doubled such as のの and がが.
+const doubledParticles = "のがをにでとへ"
+
+// doubledParticleWords holds the words that contain doubled particles, such as ものの, とともに and 読むことと書くこと.
+var doubledParticleWords = []string{"ものの", "とともに", "ととの", "ののし", "ことと"}
+
+// isDoubledParticleWord determines if the doubled particle at i and i+1 is a part of one of doubledParticleWords.
+func isDoubledParticleWord(runes []rune, i int) bool {
+	for _, word := range doubledParticleWords {
+		w := []rune(word)
+		for k := 0; k+1 < len(w); k++ {
+			if w[k] != runes[i] || w[k+1] != runes[i+1] {
+				continue
+			}
+			start := i - k
+			if start >= 0 && start+len(w) <= len(runes) && string(runes[start:start+len(w)]) == word {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lintDoubledParticles reports the particles that are doubled such as これのの and ここでで.
+// The words in doubledParticleWords are not reported.
+func (d *Document) lintDoubledParticles(texts []*lintText) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, t := range texts {
+		for i := 0; i+1 < len(t.runes); i++ {
+			r := t.runes[i]
+			if r != t.runes[i+1] || !strings.ContainsRune(doubledParticles, r) || isDoubledParticleWord(t.runes, i) {
+				continue
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				File:    d.FileName,
+				Line:    d.lineAt(t.offset(i)),
+				Message: fmt.Sprintf("doubled particle %q", string([]rune{r, r})),
+				Rule:    LINT_DOUBLED_PARTICLE,
+			})
+			i++
+		}
+	}
+	return diagnostics
+}
+
+// lintSpacing reports Japanese and Latin words that are not separated by a space if spacing is "always",
+// and the ones that are separated by spaces if spacing is "never".
+func (d *Document) lintSpacing(texts []*lintText, spacing string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, t := range texts {
+		for i := 1; i < len(t.runes); i++ {
+			prev, r := t.runes[i-1], t.runes[i]
+			switch spacing {
+			case LINT_SPACING_ALWAYS:
+				if isJapanese(prev) && isLatin(r) || isLatin(prev) && isJapanese(r) {
+					diagnostics = append(diagnostics, Diagnostic{
+						File:    d.FileName,
+						Line:    d.lineAt(t.offset(i)),
+						Message: fmt.Sprintf("no space between %q and %q", string(prev), string(r)),
+						Rule:    LINT_SPACING,
+					})
+				}
+			case LINT_SPACING_NEVER:
+				if r != ' ' {
+					continue
+				}
+				end := i
+				for end < len(t.runes) && t.runes[end] == ' ' {
+					end++
+				}
+				if end < len(t.runes) && (isJapanese(prev) && isLatin(t.runes[end]) || isLatin(prev) && isJapanese(t.runes[end])) {
+					diagnostics = append(diagnostics, Diagnostic{
+						File:    d.FileName,
+						Line:    d.lineAt(t.offset(i)),
+						Message: fmt.Sprintf("space between %q and %q", string(prev), string(t.runes[end])),
+						Rule:    LINT_SPACING,
+					})
+				}
+				i = end - 1
+			}
+		}
+	}
+	return diagnostics
+}
+
+// Lint checks the Japanese text of the document and returns the problems.
+// Code, formulas and HTML are not checked.
+func (d *Document) Lint(opts LintOptions) []Diagnostic {
+	texts := d.lintTexts()
+	diagnostics := []Diagnostic{}
+	if opts.enabled(LINT_STYLE) {
+		diagnostics = append(diagnostics, d.lintStyle(texts)...)
+	}
+	if opts.enabled(LINT_WIDTH) {
+		diagnostics = append(diagnostics, d.lintWidth(texts)...)
+	}
+	if opts.enabled(LINT_SENTENCE_LENGTH) && opts.MaxSentenceLength > 0 {
+		diagnostics = append(diagnostics, d.lintSentenceLength(texts, opts.MaxSentenceLength)...)
+	}
+	if opts.enabled(LINT_DOUBLED_PARTICLE) {
+		diagnostics = append(diagnostics, d.lintDoubledParticles(texts)...)
+	}
+	if opts.enabled(LINT_SPACING) && opts.Spacing != "" {
+		diagnostics = append(diagnostics, d.lintSpacing(texts, opts.Spacing)...)
+	}
+	SortDiagnostics(diagnostics)
+	return diagnostics
+}
+
+// LintFiles reads markdown files and checks their Japanese text.
+func LintFiles(markDownFileNames []string, opts LintOptions) ([]Diagnostic, error) {
+	docs, err := ParseDocuments(markDownFileNames)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := []Diagnostic{}
+	for _, doc := range docs {
+		diagnostics = append(diagnostics, doc.Lint(opts)...)
+	}
+	SortDiagnostics(diagnostics)
+	return diagnostics, nil
+}
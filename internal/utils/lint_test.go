@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLintRules(t *testing.T) {
+	got, err := ParseLintRules(" width, sentence-length ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{LINT_WIDTH: true, LINT_SENTENCE_LENGTH: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLintRules() = %v, want %v", got, want)
+	}
+	if _, err := ParseLintRules("width,typo"); err == nil {
+		t.Error("ParseLintRules() error = nil, want an error for an unknown rule")
+	}
+}
+
+func TestSentenceStyle(t *testing.T) {
+	tests := []struct {
+		sentence string
+		want     string
+	}{
+		{sentence: "これはペンです。", want: politeStyle},
+		{sentence: "実行してください。", want: politeStyle},
+		{sentence: "使えません！", want: politeStyle},
+		{sentence: "これはペンだ。", want: plainStyle},
+		{sentence: "これはペンである", want: plainStyle},
+		{sentence: "実行できない。", want: plainStyle},
+		{sentence: "「これはペンだ。」", want: ""},
+		{sentence: "ペン。", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sentence, func(t *testing.T) {
+			if got := sentenceStyle(tt.sentence); got != tt.want {
+				t.Errorf("sentenceStyle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_Lint(t *testing.T) {
+	tests := []struct {
+		name string
+		opts LintOptions
+		md   string
+		want []Diagnostic
+	}{
+		{
+			name: "Style",
+			md:   "これはペンです。\nこれは本だ。これは机です。\n\n「これは本だ。」と言います。",
+			want: []Diagnostic{
+				{File: "a.md", Line: 4, Message: `sentence in "だ・である" style in a page written in "です・ます" style`, Rule: LINT_STYLE},
+			},
+		},
+		{
+			name: "Width",
+			md:   "Ｇｏ言語とGoとRustを使う。\n日本語,英語を学ぶ，そして話す。\n\n1.5、2.0と上がった。",
+			want: []Diagnostic{
+				{File: "a.md", Line: 3, Message: `full-width alphanumerics "Ｇｏ" in a page that uses half-width ones`, Rule: LINT_WIDTH},
+				{File: "a.md", Line: 4, Message: `half-width "," after Japanese text`, Rule: LINT_WIDTH},
+				{File: "a.md", Line: 4, Message: `"，" is mixed with "、"`, Rule: LINT_WIDTH},
+			},
+		},
+		{
+			name: "Sentence length",
+			opts: LintOptions{MaxSentenceLength: 10},
+			md:   "これは短い文です。\nこれはとても長い\n文です。\n\nThis is a long English sentence.",
+			want: []Diagnostic{
+				{File: "a.md", Line: 4, Message: "sentence is 12 characters long (max 10)", Rule: LINT_SENTENCE_LENGTH},
+			},
+		},
+		{
+			name: "Doubled particles",
+			md:   "日本のの例です。\n友人とともに学ぶものの、問題がが残る。\n\n`日本のの例`と$x$",
+			want: []Diagnostic{
+				{File: "a.md", Line: 3, Message: `doubled particle "のの"`, Rule: LINT_DOUBLED_PARTICLE},
+				{File: "a.md", Line: 4, Message: `doubled particle "がが"`, Rule: LINT_DOUBLED_PARTICLE},
+			},
+		},
+		{
+			name: "Doubled particles after hiragana",
+			md:   "これのの例です。\nここでで会う。\n読むことと書くことを整える。ののしる。",
+			want: []Diagnostic{
+				{File: "a.md", Line: 3, Message: `doubled particle "のの"`, Rule: LINT_DOUBLED_PARTICLE},
+				{File: "a.md", Line: 4, Message: `doubled particle "でで"`, Rule: LINT_DOUBLED_PARTICLE},
+			},
+		},
+		{
+			name: "Doubled particles at the start of a block",
+			md:   "がが残る。\n\n- にに行く\n- ととのえる",
+			want: []Diagnostic{
+				{File: "a.md", Line: 3, Message: `doubled particle "がが"`, Rule: LINT_DOUBLED_PARTICLE},
+				{File: "a.md", Line: 5, Message: `doubled particle "にに"`, Rule: LINT_DOUBLED_PARTICLE},
+			},
+		},
+		{
+			name: "Spacing always",
+			opts: LintOptions{Spacing: LINT_SPACING_ALWAYS},
+			md:   "Go言語と Rust を使う。\n\n```\nGo言語\n```",
+			want: []Diagnostic{
+				{File: "a.md", Line: 3, Message: `no space between "o" and "言"`, Rule: LINT_SPACING},
+			},
+		},
+		{
+			name: "Spacing never",
+			opts: LintOptions{Spacing: LINT_SPACING_NEVER},
+			md:   "Go言語と Rust を使う。",
+			want: []Diagnostic{
+				{File: "a.md", Line: 3, Message: `space between "と" and "R"`, Rule: LINT_SPACING},
+				{File: "a.md", Line: 3, Message: `space between "t" and "を"`, Rule: LINT_SPACING},
+			},
+		},
+		{
+			name: "Disabled",
+			opts: LintOptions{Disabled: map[string]bool{LINT_STYLE: true, LINT_DOUBLED_PARTICLE: true}},
+			md:   "これはペンです。これは本だ。日本のの例です。",
+			want: []Diagnostic{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureMarkdown(MarkdownOptions{Math: true})
+			defer ConfigureMarkdown(MarkdownOptions{})
+			doc, err := ParseDocument("a.md", []byte("{}\n---\n"+tt.md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Lint(tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.Lint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindMathInline is the NodeKind of MathInline.
+var KindMathInline = ast.NewNodeKind("MathInline")
+
+// MathInline is a formula such as $x^2$ in a paragraph.
+// Its child is the text of the formula.
+type MathInline struct {
+	ast.BaseInline
+	// Display is true if the formula is written as $$...$$.
+	Display bool
+}
+
+func (n *MathInline) Kind() ast.NodeKind {
+	return KindMathInline
+}
+
+func (n *MathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// KindMathBlock is the NodeKind of MathBlock.
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// MathBlock is a formula written between lines that start with $$.
+// Its lines are the text of the formula.
+type MathBlock struct {
+	ast.BaseBlock
+	// closed is true if the closing $$ has been read.
+	closed bool
+}
+
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+func (n *MathBlock) IsRaw() bool {
+	return true
+}
+
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathInlineParser parses $...$ and $$...$$ in a line.
+// Like Pandoc, the opening $ must not be followed by a space, and the closing $ must not be preceded by a space or followed by a digit,
+// so that prices such as $5 and $10 are not formulas.
+type mathInlineParser struct{}
+
+func (p mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if bytes.HasPrefix(line, []byte("$$")) {
+		stop := bytes.Index(line[2:], []byte("$$"))
+		if stop <= 0 {
+			return nil
+		}
+		node := &MathInline{Display: true}
+		node.AppendChild(node, ast.NewTextSegment(text.NewSegment(segment.Start+2, segment.Start+2+stop)))
+		block.Advance(stop + 4)
+		return node
+	}
+	if len(line) < 3 || util.IsSpace(line[1]) || line[1] == '$' {
+		return nil
+	}
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '$':
+			if util.IsSpace(line[i-1]) || i+1 < len(line) && '0' <= line[i+1] && line[i+1] <= '9' {
+				continue
+			}
+			node := &MathInline{}
+			node.AppendChild(node, ast.NewTextSegment(text.NewSegment(segment.Start+1, segment.Start+i)))
+			block.Advance(i + 1)
+			return node
+		}
+	}
+	return nil
+}
+
+// mathFenceRe matches a line that starts with $$.
+var mathFenceRe = regexp.MustCompile(`^ {0,3}\$\$`)
+
+// mathBlockParser parses formulas that start with $$ at the start of a line and end with $$ at the end of a line.
+type mathBlockParser struct{}
+
+func (p mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// appendUntilClosing appends the part of a line to the block up to the closing $$.
+// It returns true if the line has the closing $$.
+func (p mathBlockParser) appendUntilClosing(node *MathBlock, line []byte, start int) bool {
+	trimmed := util.TrimRightSpace(line)
+	closed := bytes.HasSuffix(trimmed, []byte("$$"))
+	stop := len(line)
+	if closed {
+		stop = len(trimmed) - 2
+	}
+	if stop > 0 {
+		node.Lines().Append(text.NewSegment(start, start+stop))
+	}
+	return closed
+}
+
+func (p mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	loc := mathFenceRe.FindIndex(line)
+	if loc == nil {
+		return nil, parser.NoChildren
+	}
+	rest := line[loc[1]:]
+	i := bytes.Index(rest, []byte("$$"))
+	if i >= 0 && !util.IsBlank(rest[i+2:]) {
+		// The line such as "$$x$$ text" is a paragraph that starts with a formula.
+		return nil, parser.NoChildren
+	}
+	node := &MathBlock{}
+	node.closed = p.appendUntilClosing(node, rest, segment.Start+loc[1])
+	advanceLine(reader, line, segment)
+	return node, parser.NoChildren
+}
+
+func (p mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*MathBlock)
+	if n.closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	n.closed = p.appendUntilClosing(n, line, segment.Start)
+	advanceLine(reader, line, segment)
+	return parser.Continue | parser.NoChildren
+}
+
+func (p mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mathText returns the text of a formula.
+func mathText(node ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	if node.Type() == ast.TypeBlock {
+		for i := 0; i < node.Lines().Len(); i++ {
+			line := node.Lines().At(i)
+			buf.Write(line.Value(source))
+		}
+	} else if t, ok := node.FirstChild().(*ast.Text); ok {
+		buf.Write(t.Segment.Value(source))
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func (r customRenderer) renderMathInline(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, err := w.WriteString(texToMathML(mathText(node, source), node.(*MathInline).Display))
+		if err != nil {
+			return 0, err
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r customRenderer) renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, err := w.WriteString(texToMathML(mathText(node, source), true) + "\n")
+		if err != nil {
+			return 0, err
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMath(t *testing.T) {
+	x := `<mrow><mi>x</mi></mrow><annotation encoding="application/x-tex">x</annotation></semantics></math>`
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{
+			name: "Inline",
+			md:   "a $x$ b $$x$$",
+			want: "<p>a <math><semantics>" + x + " b <math display=\"block\"><semantics>" + x + "</p>\n",
+		},
+		{
+			name: "Prices",
+			md:   "$5 and $10\n\n$ x$, $x $",
+			want: "<p>$5 and $10</p>\n<p>$ x$, $x $</p>\n",
+		},
+		{
+			name: "Escaped and code",
+			md:   "\\$x$ `$x$`",
+			want: "<p>$x$ <code>$x$</code></p>\n",
+		},
+		{
+			name: "Block",
+			md:   "a\n$$\nx\n$$\nb",
+			want: "<p>a</p>\n<math display=\"block\"><semantics>" + x + "\n<p>b</p>\n",
+		},
+		{
+			name: "One-line block",
+			md:   "$$ x $$",
+			want: "<math display=\"block\"><semantics>" + x + "\n",
+		},
+		{
+			name: "Multi-line block",
+			md:   "$$ x\n\\\\ y $$",
+			want: "<math display=\"block\"><semantics><mrow><mtable><mtr><mtd><mi>x</mi></mtd></mtr><mtr><mtd><mi>y</mi></mtd></mtr></mtable></mrow>" +
+				"<annotation encoding=\"application/x-tex\">x\n\\\\ y</annotation></semantics></math>\n",
+		},
+	}
+	ConfigureMarkdown(MarkdownOptions{Math: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateHTML_Math(t *testing.T) {
+	ConfigureMarkdown(MarkdownOptions{Math: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	doc := ParseMarkdown("# T\n\n$$\n\\begin{cases} \\frac{1}{2} & \\mathrm{d} \\\\ \\hat{x}_i, \\quad \\left(\\sum\\right) \\end{cases}\n$$\n\nとなる。")
+	body, err := doc.HTML()
+	if err != nil {
+		t.Errorf("Document.HTML() error = %v", err)
+		return
+	}
+	got := CreateHTML(BODY, "", body, "", "", "", "", "", "")
+	math := body[strings.Index(body, "<math"):]
+	if !strings.Contains(got, math) {
+		t.Errorf("CreateHTML() = %v, want %v", got, math)
+	}
+	if got, want := doc.Description(), "となる。"; got != want {
+		t.Errorf("Document.Description() = %v, want %v", got, want)
+	}
+}
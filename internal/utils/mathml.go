@@ -0,0 +1,554 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// texIdentifiers maps the LaTeX commands of letters and symbols to the characters rendered as <mi>.
+var texIdentifiers = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ",
+	"rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ",
+	"phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"infty": "∞", "partial": "∂", "nabla": "∇", "emptyset": "∅", "varnothing": "∅",
+	"ell": "ℓ", "hbar": "ℏ", "aleph": "ℵ", "Re": "ℜ", "Im": "ℑ",
+}
+
+// texUprightIdentifiers maps the LaTeX commands of uppercase Greek letters, which are not italic.
+var texUprightIdentifiers = map[string]string{
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// texOperators maps the LaTeX commands of operators, relations, arrows and delimiters to the characters rendered as <mo>.
+var texOperators = map[string]string{
+	"times": "×", "div": "÷", "cdot": "⋅", "pm": "±", "mp": "∓", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "∙", "oplus": "⊕", "otimes": "⊗", "cup": "∪", "cap": "∩",
+	"setminus": "∖", "wedge": "∧", "land": "∧", "vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬",
+	"le": "≤", "leq": "≤", "ge": "≥", "geq": "≥", "ne": "≠", "neq": "≠", "approx": "≈",
+	"equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫",
+	"prec": "≺", "succ": "≻", "in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "supset": "⊃",
+	"subseteq": "⊆", "supseteq": "⊇", "to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←",
+	"leftrightarrow": "↔", "Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "iff": "⇔",
+	"implies": "⟹", "mapsto": "↦", "uparrow": "↑", "downarrow": "↓", "longrightarrow": "⟶",
+	"longleftarrow": "⟵", "forall": "∀", "exists": "∃", "nexists": "∄", "mid": "∣",
+	"parallel": "∥", "perp": "⊥", "angle": "∠", "triangle": "△", "therefore": "∴", "because": "∵",
+	"dots": "…", "ldots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"vert": "|", "lvert": "|", "rvert": "|", "Vert": "‖", "lVert": "‖", "rVert": "‖",
+	"colon": ":", "prime": "′",
+	"{": "{", "}": "}", "|": "‖", "%": "%", "$": "$", "#": "#", "&": "&", "_": "_",
+}
+
+// texLargeOperators maps the LaTeX commands of large operators to their characters.
+// The value is true if their scripts are placed below and above them in display math.
+var texLargeOperators = map[string]struct {
+	char   string
+	limits bool
+}{
+	"sum": {"∑", true}, "prod": {"∏", true}, "coprod": {"∐", true},
+	"bigcup": {"⋃", true}, "bigcap": {"⋂", true}, "bigoplus": {"⨁", true}, "bigotimes": {"⨂", true},
+	"int": {"∫", false}, "iint": {"∬", false}, "iiint": {"∭", false}, "oint": {"∮", false},
+}
+
+// texFunctions holds the LaTeX commands of functions such as \sin.
+// The value is true if their scripts are placed below and above them in display math like \lim.
+var texFunctions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false,
+	"arcsin": false, "arccos": false, "arctan": false, "sinh": false, "cosh": false, "tanh": false,
+	"log": false, "ln": false, "lg": false, "exp": false, "deg": false, "dim": false,
+	"ker": false, "arg": false, "hom": false,
+	"lim": true, "liminf": true, "limsup": true, "max": true, "min": true, "sup": true,
+	"inf": true, "det": true, "gcd": true, "Pr": true,
+}
+
+// texSpaces maps the LaTeX commands of spaces to their widths.
+var texSpaces = map[string]string{
+	",": "0.167em", ":": "0.222em", ">": "0.222em", ";": "0.278em", " ": "0.333em",
+	"!": "-0.167em", "quad": "1em", "qquad": "2em",
+}
+
+// texAccents maps the LaTeX commands of accents to the characters placed above their arguments.
+var texAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "‾", "overline": "‾", "vec": "→", "overrightarrow": "→",
+	"dot": "˙", "ddot": "¨", "tilde": "~", "widetilde": "~",
+}
+
+// texIgnored holds the LaTeX commands that do not change the MathML output.
+var texIgnored = map[string]bool{
+	"displaystyle": true, "textstyle": true, "limits": true, "nolimits": true,
+	"big": true, "Big": true, "bigg": true, "Bigg": true,
+	"bigl": true, "bigr": true, "Bigl": true, "Bigr": true, "biggl": true, "biggr": true,
+}
+
+// texMatrixDelimiters maps the environments of matrices to their left and right delimiters.
+var texMatrixDelimiters = map[string][2]string{
+	"matrix": {"", ""}, "smallmatrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"},
+	"Bmatrix": {"{", "}"}, "vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"}, "cases": {"{", ""},
+	"array": {"", ""}, "aligned": {"", ""}, "align": {"", ""}, "align*": {"", ""},
+	"gathered": {"", ""}, "gather": {"", ""}, "gather*": {"", ""}, "split": {"", ""},
+}
+
+// texFonts maps the LaTeX commands of fonts to the first code points of their uppercase letters, lowercase letters and digits
+// in the Mathematical Alphanumeric Symbols block, and to the characters that are defined outside the block.
+var texFonts = map[string]struct {
+	upper, lower, digit rune
+	exceptions          map[rune]rune
+}{
+	"mathbf":     {0x1D400, 0x1D41A, 0x1D7CE, nil},
+	"boldsymbol": {0x1D400, 0x1D41A, 0x1D7CE, nil},
+	"mathbb": {0x1D538, 0x1D552, 0x1D7D8, map[rune]rune{
+		'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ',
+	}},
+	"mathcal": {0x1D49C, 0x1D4B6, 0, map[rune]rune{
+		'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ',
+		'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ',
+	}},
+	"mathsf": {0x1D5A0, 0x1D5BA, 0x1D7E2, nil},
+	"mathtt": {0x1D670, 0x1D68A, 0x1D7F6, nil},
+}
+
+// mathMLElements holds the MathML elements that texToMathML emits.
+var mathMLElements = []string{
+	"math", "semantics", "annotation", "mrow", "mi", "mn", "mo", "mtext", "mspace", "merror",
+	"msub", "msup", "msubsup", "munder", "mover", "munderover", "mfrac", "msqrt", "mroot",
+	"mtable", "mtr", "mtd",
+}
+
+// texParser converts a LaTeX formula into MathML.
+// It accepts the commonly used subset of LaTeX math. An unknown command is rendered as <merror>.
+type texParser struct {
+	src     []rune
+	pos     int
+	display bool
+	// font is the font command such as "mathbf" applied to letters and digits.
+	font string
+}
+
+// texToMathML converts a LaTeX formula into a <math> element.
+// If display is true, the formula is rendered as a block. The formula is kept as an annotation.
+func texToMathML(tex string, display bool) string {
+	p := &texParser{src: []rune(tex), display: display}
+	rows := []string{""}
+	for {
+		rows[len(rows)-1] += p.parseRow()
+		if p.eof() {
+			break
+		}
+		// `\\` breaks the line, and an unmatched terminator such as "}" or `\right` is skipped.
+		switch {
+		case p.consume(`\\`):
+			rows = append(rows, "")
+		case p.peek() == '\\':
+			p.readCommand()
+		default:
+			p.pos++
+		}
+	}
+	body := rows[0]
+	if len(rows) > 1 {
+		body = "<mtable><mtr><mtd>" + strings.Join(rows, "</mtd></mtr><mtr><mtd>") + "</mtd></mtr></mtable>"
+	}
+	attr := ""
+	if display {
+		attr = ` display="block"`
+	}
+	return fmt.Sprintf(`<math%s><semantics><mrow>%s</mrow><annotation encoding="application/x-tex">%s</annotation></semantics></math>`,
+		attr, body, html.EscapeString(tex))
+}
+
+func (p *texParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *texParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// hasPrefix determines if the rest of the formula starts with s.
+func (p *texParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(string(p.src[min(p.pos, len(p.src)):]), s)
+}
+
+// consume skips s if the rest of the formula starts with s.
+func (p *texParser) consume(s string) bool {
+	if !p.hasPrefix(s) {
+		return false
+	}
+	p.pos += len([]rune(s))
+	return true
+}
+
+func (p *texParser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// atTerminator determines if the parser is at the end of a row such as "}", "&", `\\`, `\right` or `\end`.
+func (p *texParser) atTerminator() bool {
+	if p.eof() {
+		return true
+	}
+	switch p.peek() {
+	case '}', '&':
+		return true
+	}
+	return p.hasPrefix(`\\`) || p.hasCommand("right") || p.hasCommand("end")
+}
+
+// hasCommand determines if the rest of the formula starts with the command.
+func (p *texParser) hasCommand(name string) bool {
+	if !p.hasPrefix(`\` + name) {
+		return false
+	}
+	next := p.pos + 1 + len([]rune(name))
+	return next >= len(p.src) || !unicode.IsLetter(p.src[next])
+}
+
+// parseRow parses the atoms up to a terminator.
+func (p *texParser) parseRow() string {
+	var buf strings.Builder
+	for {
+		p.skipSpaces()
+		if p.atTerminator() {
+			return buf.String()
+		}
+		buf.WriteString(p.parseScripts())
+	}
+}
+
+// parseGroup parses "{...}" and returns its content.
+func (p *texParser) parseGroup() string {
+	p.pos++
+	row := p.parseRow()
+	p.consume("}")
+	return row
+}
+
+// readCommand reads a command such as `\alpha` or `\{` and returns its name.
+func (p *texParser) readCommand() string {
+	p.pos++
+	if p.eof() {
+		return ""
+	}
+	start := p.pos
+	for !p.eof() && unicode.IsLetter(p.peek()) && p.peek() < unicode.MaxASCII {
+		p.pos++
+	}
+	if p.pos == start {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// readRawGroup reads "{...}" and returns its content as text.
+func (p *texParser) readRawGroup() string {
+	p.skipSpaces()
+	if p.peek() != '{' {
+		return ""
+	}
+	p.pos++
+	start, depth := p.pos, 0
+	for ; !p.eof(); p.pos++ {
+		switch p.peek() {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				text := string(p.src[start:p.pos])
+				p.pos++
+				return text
+			}
+			depth--
+		}
+	}
+	return string(p.src[start:])
+}
+
+// parseArg parses an argument of a command or a script, which is a group or a single atom.
+func (p *texParser) parseArg() string {
+	p.skipSpaces()
+	if p.peek() == '{' {
+		return "<mrow>" + p.parseGroup() + "</mrow>"
+	}
+	if p.atTerminator() {
+		return "<mrow></mrow>"
+	}
+	if unicode.IsDigit(p.peek()) {
+		p.pos++
+		return p.number(string(p.src[p.pos-1]))
+	}
+	atom, _ := p.parseAtom()
+	return atom
+}
+
+// parseScripts parses an atom followed by its subscript, superscript and primes.
+func (p *texParser) parseScripts() string {
+	base, limits := p.parseAtom()
+	var sub string
+	sups := []string{}
+	for {
+		p.skipSpaces()
+		switch {
+		case p.consume("_"):
+			sub = p.parseArg()
+		case p.consume("^"):
+			sups = append(sups, p.parseArg())
+		case p.consume("'"):
+			sups = append([]string{"<mo>′</mo>"}, sups...)
+		default:
+			if sub == "" && len(sups) == 0 {
+				return base
+			}
+			sup := strings.Join(sups, "")
+			if len(sups) > 1 {
+				sup = "<mrow>" + sup + "</mrow>"
+			}
+			under, over, both := "msub", "msup", "msubsup"
+			if limits && p.display {
+				under, over, both = "munder", "mover", "munderover"
+			}
+			switch {
+			case sup == "":
+				return fmt.Sprintf("<%s>%s%s</%s>", under, base, sub, under)
+			case sub == "":
+				return fmt.Sprintf("<%s>%s%s</%s>", over, base, sup, over)
+			default:
+				return fmt.Sprintf("<%s>%s%s%s</%s>", both, base, sub, sup, both)
+			}
+		}
+	}
+}
+
+// parseAtom parses an atom such as a number, a letter, a group or a command.
+// limits is true if the scripts of the atom are placed below and above it in display math.
+// At the end of the formula, it returns "" without advancing.
+func (p *texParser) parseAtom() (atom string, limits bool) {
+	if p.eof() {
+		return "", false
+	}
+	c := p.peek()
+	switch {
+	case c == '{':
+		return "<mrow>" + p.parseGroup() + "</mrow>", false
+	case c == '\\':
+		return p.parseCommand()
+	case unicode.IsDigit(c):
+		start := p.pos
+		for !p.eof() && (unicode.IsDigit(p.peek()) || p.peek() == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1])) {
+			p.pos++
+		}
+		return p.number(string(p.src[start:p.pos])), false
+	case unicode.IsLetter(c):
+		p.pos++
+		return p.identifier(string(c)), false
+	case c == '~':
+		p.pos++
+		return `<mspace width="0.333em"/>`, false
+	case c == '-':
+		p.pos++
+		return "<mo>−</mo>", false
+	case c == '*':
+		p.pos++
+		return "<mo>∗</mo>", false
+	case c == '^' || c == '_' || c == '\'':
+		// A script without a base is attached to an empty row.
+		return "<mrow></mrow>", false
+	}
+	p.pos++
+	return p.operator(string(c)), false
+}
+
+// parseCommand parses a command and its arguments.
+func (p *texParser) parseCommand() (string, bool) {
+	name := p.readCommand()
+	if s, exists := texIdentifiers[name]; exists {
+		return p.identifier(s), false
+	}
+	if s, exists := texUprightIdentifiers[name]; exists {
+		return `<mi mathvariant="normal">` + s + "</mi>", false
+	}
+	if s, exists := texOperators[name]; exists {
+		return p.operator(s), false
+	}
+	if op, exists := texLargeOperators[name]; exists {
+		return `<mo largeop="true">` + op.char + "</mo>", op.limits
+	}
+	if limits, exists := texFunctions[name]; exists {
+		return "<mi>" + name + "</mi>", limits
+	}
+	if width, exists := texSpaces[name]; exists {
+		return `<mspace width="` + width + `"/>`, false
+	}
+	if accent, exists := texAccents[name]; exists {
+		return `<mover accent="true">` + p.parseArg() + "<mo>" + accent + "</mo></mover>", false
+	}
+	if _, exists := texFonts[name]; exists {
+		return p.withFont(name), false
+	}
+	if texIgnored[name] {
+		return "", false
+	}
+	switch name {
+	case "mathrm", "mathit":
+		return p.withFont(name), false
+	case "frac", "dfrac", "tfrac", "cfrac":
+		return "<mfrac>" + p.parseArg() + p.parseArg() + "</mfrac>", false
+	case "binom":
+		return `<mrow><mo>(</mo><mfrac linethickness="0">` + p.parseArg() + p.parseArg() + "</mfrac><mo>)</mo></mrow>", false
+	case "sqrt":
+		p.skipSpaces()
+		if p.consume("[") {
+			var index strings.Builder
+			for !p.eof() && !p.consume("]") {
+				index.WriteString(p.parseScripts())
+			}
+			return "<mroot>" + p.parseArg() + "<mrow>" + index.String() + "</mrow></mroot>", false
+		}
+		return "<msqrt>" + p.parseArg() + "</msqrt>", false
+	case "underline":
+		return `<munder accentunder="true">` + p.parseArg() + "<mo>_</mo></munder>", false
+	case "text", "textrm", "textnormal", "mbox":
+		return "<mtext>" + html.EscapeString(p.readRawGroup()) + "</mtext>", false
+	case "operatorname":
+		return "<mi>" + html.EscapeString(p.readRawGroup()) + "</mi>", false
+	case "not":
+		p.skipSpaces()
+		atom, limits := p.parseAtom()
+		return strings.Replace(atom, "</mo>", "̸</mo>", 1), limits
+	case "left":
+		left := p.delimiter()
+		row := p.parseRow()
+		right := ""
+		if p.consume(`\right`) {
+			right = p.delimiter()
+		}
+		return "<mrow>" + left + row + right + "</mrow>", false
+	case "begin":
+		return p.parseEnvironment(p.readRawGroup()), false
+	}
+	return "<merror><mtext>" + html.EscapeString(`\`+name) + "</mtext></merror>", false
+}
+
+// withFont parses the argument of a font command such as \mathbf.
+func (p *texParser) withFont(font string) string {
+	outer := p.font
+	p.font = font
+	defer func() { p.font = outer }()
+	return p.parseArg()
+}
+
+// delimiter parses the delimiter after \left or \right. The delimiter "." is empty.
+func (p *texParser) delimiter() string {
+	p.skipSpaces()
+	if p.eof() {
+		return ""
+	}
+	var d string
+	if p.peek() == '\\' {
+		d = texOperators[p.readCommand()]
+	} else {
+		d = string(p.peek())
+		p.pos++
+	}
+	if d == "" || d == "." {
+		return ""
+	}
+	return `<mo fence="true" stretchy="true">` + html.EscapeString(d) + "</mo>"
+}
+
+// parseEnvironment parses the rows of an environment such as "pmatrix" up to its \end.
+func (p *texParser) parseEnvironment(name string) string {
+	if name == "array" {
+		p.readRawGroup()
+	}
+	rows := []string{}
+	cells := []string{}
+	for {
+		cells = append(cells, "<mtd>"+p.parseRow()+"</mtd>")
+		switch {
+		case p.consume("&"):
+			continue
+		case p.consume(`\\`):
+			rows = append(rows, "<mtr>"+strings.Join(cells, "")+"</mtr>")
+			cells = []string{}
+			continue
+		case p.hasCommand("end"):
+			p.readCommand()
+			p.readRawGroup()
+		}
+		// An unmatched terminator such as "}" also ends the environment.
+		break
+	}
+	if len(cells) > 1 || cells[0] != "<mtd></mtd>" {
+		rows = append(rows, "<mtr>"+strings.Join(cells, "")+"</mtr>")
+	}
+	attr := ""
+	switch name {
+	case "cases":
+		attr = ` columnalign="left left"`
+	case "aligned", "align", "align*", "split":
+		attr = ` columnalign="right left"`
+	}
+	table := "<mtable" + attr + ">" + strings.Join(rows, "") + "</mtable>"
+	delimiters, exists := texMatrixDelimiters[name]
+	if !exists {
+		return "<merror><mtext>" + html.EscapeString(name) + "</mtext></merror>" + table
+	}
+	left, right := "", ""
+	if delimiters[0] != "" {
+		left = `<mo fence="true" stretchy="true">` + delimiters[0] + "</mo>"
+	}
+	if delimiters[1] != "" {
+		right = `<mo fence="true" stretchy="true">` + delimiters[1] + "</mo>"
+	}
+	return "<mrow>" + left + table + right + "</mrow>"
+}
+
+// identifier renders letters as <mi> in the current font.
+func (p *texParser) identifier(s string) string {
+	if p.font == "mathrm" {
+		return `<mi mathvariant="normal">` + html.EscapeString(s) + "</mi>"
+	}
+	return "<mi>" + html.EscapeString(p.styled(s)) + "</mi>"
+}
+
+// number renders digits as <mn> in the current font.
+func (p *texParser) number(s string) string {
+	return "<mn>" + p.styled(s) + "</mn>"
+}
+
+// operator renders an operator as <mo>.
+func (p *texParser) operator(s string) string {
+	return "<mo>" + html.EscapeString(s) + "</mo>"
+}
+
+// styled converts the ASCII letters and digits in s into the characters of the current font.
+func (p *texParser) styled(s string) string {
+	font, exists := texFonts[p.font]
+	if !exists {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if c, exists := font.exceptions[r]; exists {
+			return c
+		}
+		switch {
+		case 'A' <= r && r <= 'Z':
+			return font.upper + r - 'A'
+		case 'a' <= r && r <= 'z':
+			return font.lower + r - 'a'
+		case '0' <= r && r <= '9' && font.digit != 0:
+			return font.digit + r - '0'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeXToMathML(t *testing.T) {
+	tests := []struct {
+		name    string
+		tex     string
+		display bool
+		want    string
+	}{
+		{
+			name: "Scripts",
+			tex:  "x_i^2 + y'' - 10.5",
+			want: "<msubsup><mi>x</mi><mi>i</mi><mn>2</mn></msubsup><mo>+</mo><msup><mi>y</mi><mrow><mo>′</mo><mo>′</mo></mrow></msup><mo>−</mo><mn>10.5</mn>",
+		},
+		{
+			name: "Single digit script",
+			tex:  "2^10",
+			want: "<msup><mn>2</mn><mn>1</mn></msup><mn>0</mn>",
+		},
+		{
+			name: "Commands",
+			tex:  `\alpha \leq \Omega \times \sin x \, \text{if $a<b$}`,
+			want: `<mi>α</mi><mo>≤</mo><mi mathvariant="normal">Ω</mi><mo>×</mo><mi>sin</mi><mi>x</mi><mspace width="0.167em"/><mtext>if $a&lt;b$</mtext>`,
+		},
+		{
+			name: "Fraction and root",
+			tex:  `\frac{1}{2} \sqrt{x} \sqrt[n]{y}`,
+			want: "<mfrac><mrow><mn>1</mn></mrow><mrow><mn>2</mn></mrow></mfrac><msqrt><mrow><mi>x</mi></mrow></msqrt><mroot><mrow><mi>y</mi></mrow><mrow><mi>n</mi></mrow></mroot>",
+		},
+		{
+			name: "Inline sum",
+			tex:  `\sum_{i=1}^n i`,
+			want: `<msubsup><mo largeop="true">∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></msubsup><mi>i</mi>`,
+		},
+		{
+			name:    "Display sum",
+			tex:     `\lim_{x \to 0} \int_0^1`,
+			display: true,
+			want:    `<munder><mi>lim</mi><mrow><mi>x</mi><mo>→</mo><mn>0</mn></mrow></munder><msubsup><mo largeop="true">∫</mo><mn>0</mn><mn>1</mn></msubsup>`,
+		},
+		{
+			name: "Fonts and accents",
+			tex:  `\mathbb{R} \mathbf{v1} \mathrm{d}x \vec{a}`,
+			want: `<mrow><mi>ℝ</mi></mrow><mrow><mi>𝐯</mi><mn>𝟏</mn></mrow><mrow><mi mathvariant="normal">d</mi></mrow><mi>x</mi><mover accent="true"><mrow><mi>a</mi></mrow><mo>→</mo></mover>`,
+		},
+		{
+			name: "Delimiters",
+			tex:  `\left( x \right. \not=`,
+			want: `<mrow><mo fence="true" stretchy="true">(</mo><mi>x</mi></mrow><mo>≠</mo>`,
+		},
+		{
+			name: "Matrix",
+			tex:  `\begin{pmatrix} a & b \\ c & d \end{pmatrix}`,
+			want: `<mrow><mo fence="true" stretchy="true">(</mo><mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr><mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr></mtable><mo fence="true" stretchy="true">)</mo></mrow>`,
+		},
+		{
+			name: "Cases",
+			tex:  `\begin{cases} 1 & x > 0 \\ 0 \end{cases}`,
+			want: `<mrow><mo fence="true" stretchy="true">{</mo><mtable columnalign="left left"><mtr><mtd><mn>1</mn></mtd><mtd><mi>x</mi><mo>&gt;</mo><mn>0</mn></mtd></mtr><mtr><mtd><mn>0</mn></mtd></mtr></mtable></mrow>`,
+		},
+		{
+			name: "Line breaks",
+			tex:  `a \\ b`,
+			want: `<mtable><mtr><mtd><mi>a</mi></mtd></mtr><mtr><mtd><mi>b</mi></mtd></mtr></mtable>`,
+		},
+		{
+			name: "Trailing not",
+			tex:  `a \not`,
+			want: `<mi>a</mi>`,
+		},
+		{
+			name: "Trailing frac",
+			tex:  `\frac`,
+			want: `<mfrac><mrow></mrow><mrow></mrow></mfrac>`,
+		},
+		{
+			name: "Trailing superscript",
+			tex:  `x^`,
+			want: `<msup><mi>x</mi><mrow></mrow></msup>`,
+		},
+		{
+			name: "Trailing subscript",
+			tex:  `y_`,
+			want: `<msub><mi>y</mi><mrow></mrow></msub>`,
+		},
+		{
+			name: "Errors",
+			tex:  `\unknown{x} } \begin{foo} a`,
+			want: `<merror><mtext>\unknown</mtext></merror><mrow><mi>x</mi></mrow><merror><mtext>foo</mtext></merror><mtable><mtr><mtd><mi>a</mi></mtd></mtr></mtable>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := texToMathML(tt.tex, tt.display)
+			body := strings.TrimPrefix(strings.TrimPrefix(got, `<math display="block">`), "<math>")
+			body = strings.TrimPrefix(body, "<semantics><mrow>")
+			body, _, _ = strings.Cut(body, "</mrow><annotation")
+			if body != tt.want {
+				t.Errorf("texToMathML() = %v, want %v", body, tt.want)
+			}
+			if tt.display != strings.HasPrefix(got, `<math display="block">`) {
+				t.Errorf("texToMathML() = %v, want display %v", got, tt.display)
+			}
+		})
+	}
+}
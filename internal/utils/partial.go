@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+)
+
+var (
+	// includeDirectiveRe matches a line that consists only of an include directive such as
+	// {{< include "_partials/setup.md" >}}.
+	includeDirectiveRe = regexp.MustCompile(`^[ \t]*\{\{<\s*include\s+"([^"]+)"\s*>\}\}\s*$`)
+	// fenceRe matches the fence of a fenced code block.
+	fenceRe = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// expandIncludes replaces the include directives in markdown text with the content of the included files.
+// The included files are relative to the file that includes them, and their include directives are expanded too.
+// Include directives in fenced code blocks are left as they are.
+// It returns the expanded text, the line in fileName of each line of the expanded text,
+// the file that each line of the expanded text comes from and the included files.
+// lineOffset is the number of lines before md in fileName, and stack holds the files being expanded to detect cycles.
+func expandIncludes(fileName, md string, lineOffset int, stack []string) (string, []int, []string, []string, error) {
+	var b strings.Builder
+	lineMap := []int{}
+	lineFiles := []string{}
+	inputs := []string{}
+	fence := ""
+	for i, line := range strings.SplitAfter(md, "\n") {
+		if line == "" {
+			continue
+		}
+		lineNumber := lineOffset + i + 1
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			if fence == "" {
+				fence = m[1]
+			} else if m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(line[len(m[0]):]) == "" {
+				fence = ""
+			}
+		}
+		m := includeDirectiveRe.FindStringSubmatch(line)
+		if fence != "" || m == nil {
+			b.WriteString(line)
+			lineMap = append(lineMap, lineNumber)
+			lineFiles = append(lineFiles, fileName)
+			continue
+		}
+
+		included := filepath.Clean(filepath.Join(filepath.Dir(fileName), filepath.FromSlash(m[1])))
+		if slices.Contains(stack, included) {
+			cycle := strings.Join(append(stack, included), " -> ")
+			return "", nil, nil, nil, errors.Errorf("%s:%d: include cycle: %s", fileName, lineNumber, cycle)
+		}
+		content, err := os.ReadFile(included)
+		if err != nil {
+			return "", nil, nil, nil, errors.Errorf("%s:%d: %s does not exist", fileName, lineNumber, included)
+		}
+		expanded, _, includedLineFiles, includedInputs, err := expandIncludes(included, string(content), 0, append(slices.Clone(stack), included))
+		if err != nil {
+			return "", nil, nil, nil, err
+		}
+		if expanded != "" && !strings.HasSuffix(expanded, "\n") {
+			expanded += "\n"
+		}
+		b.WriteString(expanded)
+		// The lines of the included file are reported as the line of the include directive.
+		for j := 0; j < strings.Count(expanded, "\n"); j++ {
+			lineMap = append(lineMap, lineNumber)
+		}
+		lineFiles = append(lineFiles, includedLineFiles...)
+		inputs = append(inputs, included)
+		inputs = append(inputs, includedInputs...)
+	}
+	return b.String(), lineMap, lineFiles, inputs, nil
+}
+
+// isRelativePath determines if a link destination is a relative path such as "images/a.png" or "../guide/a.md".
+func isRelativePath(destination string) bool {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return false
+	}
+	path, _ := splitFragment(destination)
+	return path != "" && !strings.HasPrefix(path, "/")
+}
+
+// rebaseDestination rewrites a relative path in a partial into the path relative to the page that includes the partial.
+// The query and fragment are kept.
+func rebaseDestination(destination, partialFileName, pageFileName string) string {
+	path, rest := splitFragment(destination)
+	rel, err := filepath.Rel(filepath.Dir(pageFileName), filepath.Join(filepath.Dir(partialFileName), filepath.FromSlash(path)))
+	if err != nil {
+		return destination
+	}
+	return filepath.ToSlash(rel) + rest
+}
+
+// rebaseIncludes rewrites the relative destinations of the links and images that come from partials,
+// so that they are relative to the page like the other destinations.
+// The code blocks that come from partials include the files relative to the partials.
+// lineFiles holds the file that each line of the markdown text comes from.
+func (d *Document) rebaseIncludes(lineFiles []string) {
+	fileAt := func(offset int) string {
+		line := bytes.Count(d.Source[:max(offset, 0)], []byte("\n"))
+		if line < len(lineFiles) {
+			return lineFiles[line]
+		}
+		return d.FileName
+	}
+	_ = ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			if fileName := fileAt(d.nodeOffset(n)); fileName != d.FileName && isRelativePath(string(n.Destination)) {
+				n.Destination = []byte(rebaseDestination(string(n.Destination), fileName, d.FileName))
+			}
+		case *ast.Image:
+			if fileName := fileAt(d.nodeOffset(n)); fileName != d.FileName && isRelativePath(string(n.Destination)) {
+				n.Destination = []byte(rebaseDestination(string(n.Destination), fileName, d.FileName))
+			}
+		case *ast.FencedCodeBlock:
+			if n.Info == nil {
+				return ast.WalkSkipChildren, nil
+			}
+			if fileName := fileAt(n.Info.Segment.Start); fileName != d.FileName {
+				n.SetAttributeString("includeDir", []byte(filepath.Dir(fileName)))
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+}
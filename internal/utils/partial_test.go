@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+func TestParseDocument_Include(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"_partials/setup.md":  "## Setup\n\n{{< include \"nested.md\" >}}\n",
+		"_partials/nested.md": "### Nested\n\n[missing](missing.md)",
+		"_partials/cycle.md":  "{{< include \"../cycle.md\" >}}\n",
+		"cycle.md":            "{{< include \"_partials/cycle.md\" >}}\n",
+		"_partials/links.md": "[Guide](../guide/a.md#top) ![Image](../images/a.png) [Site](https://example.com) [Top](#top)\n\n" +
+			"```go include=\"../examples/main.go\"\n```\n",
+		"examples/main.go": "package main\n",
+	}
+	for name, content := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fileName := filepath.Join(dir, "a.md")
+	setup := filepath.Join(dir, "_partials", "setup.md")
+	nested := filepath.Join(dir, "_partials", "nested.md")
+
+	t.Run("Include", func(t *testing.T) {
+		md := "{}\n---\n# A\n\n{{< include \"_partials/setup.md\" >}}\n\n```\n{{< include \"_partials/setup.md\" >}}\n```\n\n## End\n"
+		doc, err := ParseDocument(fileName, []byte(md))
+		if err != nil {
+			t.Errorf("ParseDocument() error = %v", err)
+			return
+		}
+		headings, _ := Map(doc.Headings, func(heading Heading, _ int) (string, error) {
+			return heading.Text, nil
+		})
+		if !reflect.DeepEqual(headings, []string{"A", "Setup", "Nested", "End"}) {
+			t.Errorf("Document.Headings = %v", headings)
+		}
+		if !reflect.DeepEqual(doc.Inputs, []string{fileName, setup, nested}) {
+			t.Errorf("Document.Inputs = %v, want %v", doc.Inputs, []string{fileName, setup, nested})
+		}
+		err = ResolveLinks([]*Document{doc}, dir, "https://example.com", true)
+		if err == nil || err.Error() != fileName+":5: _partials/missing.md does not exist" {
+			t.Errorf("ResolveLinks() error = %v", err)
+		}
+		if got := doc.Line(doc.Root.LastChild()); got != 11 {
+			t.Errorf("Document.Line() = %v, want %v", got, 11)
+		}
+	})
+
+	t.Run("Relative paths", func(t *testing.T) {
+		// The paths in a partial are relative to the partial wherever it is included.
+		tests := []struct {
+			fileName string
+			include  string
+			want     []string
+		}{
+			{fileName: fileName, include: "_partials/links.md", want: []string{"guide/a.md#top", "images/a.png", "https://example.com", "#top"}},
+			{
+				fileName: filepath.Join(dir, "guide", "b.md"),
+				include:  "../_partials/links.md",
+				want:     []string{"a.md#top", "../images/a.png", "https://example.com", "#top"},
+			},
+		}
+		for _, tt := range tests {
+			docs := make([]*Document, 1)
+			if err := os.MkdirAll(filepath.Dir(tt.fileName), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(tt.fileName, []byte("{}\n---\n# A\n\n{{< include \""+tt.include+"\" >}}\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := createDocumentTask(tt.fileName, dir, docs, 0)(); err != nil {
+				t.Errorf("createDocumentTask() error = %v", err)
+				continue
+			}
+			destinations := []string{}
+			_ = ast.Walk(docs[0].Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				switch n := n.(type) {
+				case *ast.Link:
+					if entering {
+						destinations = append(destinations, string(n.Destination))
+					}
+				case *ast.Image:
+					if entering {
+						destinations = append(destinations, string(n.Destination))
+					}
+				}
+				return ast.WalkContinue, nil
+			})
+			if !reflect.DeepEqual(destinations, tt.want) {
+				t.Errorf("%s: destinations = %v, want %v", tt.fileName, destinations, tt.want)
+			}
+			if code := includedCode(docs[0].Root.LastChild().(*ast.FencedCodeBlock), docs[0].Source); code != "package main\n" {
+				t.Errorf("%s: included code = %q, want %q", tt.fileName, code, "package main\n")
+			}
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := ParseDocument(fileName, []byte("{}\n---\n# A\n\n{{< include \"missing.md\" >}}\n"))
+		want := fileName + ":5: " + filepath.Join(dir, "missing.md") + " does not exist"
+		if err == nil || err.Error() != want {
+			t.Errorf("ParseDocument() error = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		cycle := filepath.Join(dir, "cycle.md")
+		partial := filepath.Join(dir, "_partials", "cycle.md")
+		_, err := ParseDocument(cycle, []byte("{}\n---\n{{< include \"_partials/cycle.md\" >}}\n"))
+		want := partial + ":1: include cycle: " + cycle + " -> " + partial + " -> " + cycle
+		if err == nil || err.Error() != want {
+			t.Errorf("ParseDocument() error = %v, want %v", err, want)
+		}
+	})
+}
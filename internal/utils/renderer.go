@@ -2,31 +2,29 @@ package utils
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
-	"strings"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
 	"github.com/yuin/goldmark/util"
 )
 
-var parentPathRe = regexp.MustCompile(`^(\.\./)+`)
-
-func isLocalImage(imageDir, destination string) bool {
-	destination = parentPathRe.ReplaceAllString(destination, "")
-	return strings.HasPrefix(destination, imageDir+"/")
-}
-
 type customRenderer struct{}
 
 func (r customRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	reg.Register(ast.KindLink, r.renderLink)
 	reg.Register(ast.KindHeading, r.renderHeading)
 	reg.Register(ast.KindImage, r.renderImage)
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+	reg.Register(KindTOC, r.renderTOC)
+	reg.Register(KindShortcode, r.renderShortcode)
+	reg.Register(KindCallout, r.renderCallout)
+	reg.Register(KindMathInline, r.renderMathInline)
+	reg.Register(KindMathBlock, r.renderMathBlock)
+	reg.Register(KindRuby, r.renderRuby)
 }
 
 func (r customRenderer) renderLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -56,7 +54,10 @@ func (r customRenderer) renderLink(w util.BufWriter, source []byte, node ast.Nod
 func (r customRenderer) renderHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	heading := node.(*ast.Heading)
 	if entering {
-		headingID := CreateHash(string(heading.Text(source)))
+		headingID := createHeadingID(string(heading.Text(source)))
+		if id, exists := heading.AttributeString("id"); exists {
+			headingID = string(id.([]byte))
+		}
 		startTag := fmt.Sprintf(`<h%d id="%s"><a href="#%s">`, heading.Level, headingID, headingID)
 		_, err := w.WriteString(startTag)
 		if err != nil {
@@ -79,16 +80,10 @@ func (r customRenderer) renderImage(w util.BufWriter, source []byte, node ast.No
 	n := node.(*ast.Image)
 	destination := string(util.EscapeHTML(n.Destination))
 	img := ""
-	if isLocalImage(IMAGE_DIR, destination) {
-		imagePath := parentPathRe.ReplaceAllString(destination, "")
-		path := filepath.Join(os.Getenv("SOURCE_DIR"), imagePath)
-		width, height, err := ImageSize(path)
-		if err != nil {
-			fmt.Printf("%s does not exist\n", path)
-			img = fmt.Sprintf(`<img src="%s" alt="%s">`, destination, destination)
-		} else {
-			img = fmt.Sprintf(`<img loading="lazy" src="%s" alt="%s" width="%d" height="%d">`, destination, destination, width, height)
-		}
+	width, hasWidth := n.AttributeString("width")
+	height, hasHeight := n.AttributeString("height")
+	if hasWidth && hasHeight {
+		img = fmt.Sprintf(`<img loading="lazy" src="%s" alt="%s" width="%d" height="%d">`, destination, destination, width, height)
 	} else {
 		img = fmt.Sprintf(`<img src="%s" alt="%s">`, destination, destination)
 	}
@@ -99,14 +94,132 @@ func (r customRenderer) renderImage(w util.BufWriter, source []byte, node ast.No
 	return ast.WalkSkipChildren, nil
 }
 
+func (r customRenderer) renderTOC(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, err := w.WriteString(node.(*TOC).HTML + "\n")
+		if err != nil {
+			return 0, err
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+const (
+	HEADING_ID_HASH  = "hash"
+	HEADING_ID_ASCII = "ascii"
+)
+
+// MarkdownOptions configures how markdown is parsed and rendered.
+type MarkdownOptions struct {
+	HeadingID string // HeadingID is "ascii" to generate heading IDs by CreateSlug. Otherwise they are generated by CreateHash.
+	Highlight HighlightOptions
+	// CopyButton adds the "copy" class to code blocks so that the script adds copy buttons to them.
+	CopyButton bool
+	// Shortcodes holds the user-defined shortcodes. The built-in shortcodes are always available.
+	Shortcodes map[string]*Shortcode
+	// Footnote, DefinitionList, Typographer and CJK enable the goldmark extensions of the same names.
+	Footnote       bool
+	DefinitionList bool
+	Typographer    bool
+	CJK            bool
+	// DisableLinkify stops URLs such as https://example.com in text from becoming links.
+	DisableLinkify bool
+	// LinkifyProtocols holds the protocols such as "https:" that are linkified. If it is empty, goldmark's default is used.
+	LinkifyProtocols []string
+	// HardWraps renders newlines in paragraphs as <br>.
+	HardWraps bool
+	// Unsafe renders raw HTML in markdown and relaxes the sanitizer. It must be used only for trusted sources.
+	Unsafe bool
+	// Math renders formulas such as $x^2$ and $$x^2$$ as MathML.
+	Math bool
+	// DiagramCommands maps the kinds of diagrams such as "mermaid" to the commands that pre-render them.
+	// A command reads the source of a diagram from stdin and writes SVG to stdout.
+	DiagramCommands map[string][]string
+	// DiagramScript is the script that renders the diagrams without commands in the browser.
+	// If it is empty, a diagram without a command is shown as its source.
+	DiagramScript string
+	// Ruby renders the readings of Japanese text written as {漢字|かんじ} or ｜漢字《かんじ》 as ruby.
+	Ruby bool
+	// WritingMode is "vertical" to write the pages vertically. The metadata of a page overrides it.
+	WritingMode string
+	// DescriptionLength is the maximum number of characters in the descriptions generated from the text.
+	// If it is 0, DEFAULT_DESCRIPTION_LENGTH is used.
+	DescriptionLength int
+	// Languages holds the languages of the site. The first one is the default language.
+	Languages []Language
+}
+
+// extensions returns the goldmark extensions enabled by the options.
+// The extensions of GitHub Flavored Markdown (GFM) are always enabled except Linkify, which can be disabled.
+func (opts MarkdownOptions) extensions() []goldmark.Extender {
+	extensions := []goldmark.Extender{extension.Table, extension.Strikethrough, extension.TaskList}
+	if !opts.DisableLinkify {
+		linkifyOptions := []extension.LinkifyOption{}
+		if len(opts.LinkifyProtocols) > 0 {
+			linkifyOptions = append(linkifyOptions, extension.WithLinkifyAllowedProtocols(opts.LinkifyProtocols))
+		}
+		extensions = append(extensions, extension.NewLinkify(linkifyOptions...))
+	}
+	if opts.Footnote {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if opts.DefinitionList {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if opts.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+	if opts.CJK {
+		extensions = append(extensions, extension.CJK)
+	}
+	return extensions
+}
+
+var markdownOptions MarkdownOptions
+
+// ConfigureMarkdown replaces the goldmark.Markdown instance used to parse and render markdown.
+// It must be called before any markdown is parsed.
+func ConfigureMarkdown(opts MarkdownOptions) {
+	markdownOptions = opts
+	markdown = NewMarkdown(opts)
+}
+
 // NewMarkdown initializes a new goldmark.Markdown instance with custom rendering logic.
-// It includes GitHub Flavored Markdown (GFM) extensions and sets the custom renderer with high priority.
-func NewMarkdown() goldmark.Markdown {
-	option := goldmark.WithRendererOptions(renderer.WithNodeRenderers(
+// It includes GitHub Flavored Markdown (GFM) extensions and the extensions enabled by opts, and sets the custom renderer with high priority.
+// Attributes such as {#custom-id} are enabled for headings, and shortcodes, callouts and optionally formulas are parsed.
+func NewMarkdown(opts MarkdownOptions) goldmark.Markdown {
+	rendererOptions := []renderer.Option{renderer.WithNodeRenderers(
 		util.Prioritized(customRenderer{}, 200),
-	))
+	)}
+	if opts.HardWraps {
+		rendererOptions = append(rendererOptions, html.WithHardWraps())
+	}
+	if opts.Unsafe {
+		rendererOptions = append(rendererOptions, html.WithUnsafe())
+	}
+	option := goldmark.WithRendererOptions(rendererOptions...)
+	blockParsers := []util.PrioritizedValue{
+		util.Prioritized(&shortcodeParser{shortcodes: shortcodes(opts.Shortcodes)}, 850),
+		util.Prioritized(calloutParser{}, 860),
+	}
+	inlineParsers := []util.PrioritizedValue{}
+	if opts.Math {
+		blockParsers = append(blockParsers, util.Prioritized(mathBlockParser{}, 870))
+		inlineParsers = append(inlineParsers, util.Prioritized(mathInlineParser{}, 450))
+	}
+	transformers := []util.PrioritizedValue{util.Prioritized(calloutTransformer{}, 100)}
+	if opts.Ruby {
+		inlineParsers = append(inlineParsers, util.Prioritized(rubyParser{}, 460))
+		transformers = append(transformers, util.Prioritized(rubyTransformer{}, 200))
+	}
 	markdown := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithExtensions(opts.extensions()...),
+		goldmark.WithParserOptions(
+			parser.WithAttribute(),
+			parser.WithBlockParsers(blockParsers...),
+			parser.WithInlineParsers(inlineParsers...),
+			parser.WithASTTransformers(transformers...),
+		),
 		option,
 	)
 	return markdown
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMarkdown_Options(t *testing.T) {
+	tests := []struct {
+		name string
+		opts MarkdownOptions
+		md   string
+		want string
+	}{
+		{
+			name: "Default",
+			md:   "a[^1] https://example.com \"b\"\nc\n\nTerm\n: Definition\n\n<b>d</b>",
+			want: "<p>a[^1] <a href=\"https://example.com\">https://example.com</a> &quot;b&quot;\nc</p>\n<p>Term\n: Definition</p>\n<p><!-- raw HTML omitted -->d<!-- raw HTML omitted --></p>\n",
+		},
+		{
+			name: "Footnote",
+			opts: MarkdownOptions{Footnote: true},
+			md:   "a[^1]\n\n[^1]: b",
+			want: "<p>a<sup id=\"fnref:1\"><a href=\"#fn:1\" class=\"footnote-ref\" role=\"doc-noteref\">1</a></sup></p>\n" +
+				"<div class=\"footnotes\" role=\"doc-endnotes\">\n<hr>\n<ol>\n<li id=\"fn:1\">\n" +
+				"<p>b&#160;<a href=\"#fnref:1\" class=\"footnote-backref\" role=\"doc-backlink\">&#x21a9;&#xfe0e;</a></p>\n</li>\n</ol>\n</div>\n",
+		},
+		{
+			name: "Definition list",
+			opts: MarkdownOptions{DefinitionList: true},
+			md:   "Term\n: Definition",
+			want: "<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>\n",
+		},
+		{
+			name: "Typographer",
+			opts: MarkdownOptions{Typographer: true},
+			md:   "\"a\" -- b...",
+			want: "<p>&ldquo;a&rdquo; &ndash; b&hellip;</p>\n",
+		},
+		{
+			name: "CJK",
+			opts: MarkdownOptions{CJK: true},
+			md:   "日本語の\n文章です。",
+			want: "<p>日本語の文章です。</p>\n",
+		},
+		{
+			name: "Hard wraps",
+			opts: MarkdownOptions{HardWraps: true},
+			md:   "a\nb",
+			want: "<p>a<br>\nb</p>\n",
+		},
+		{
+			name: "Linkify disabled",
+			opts: MarkdownOptions{DisableLinkify: true},
+			md:   "https://example.com",
+			want: "<p>https://example.com</p>\n",
+		},
+		{
+			name: "Linkify protocols",
+			opts: MarkdownOptions{LinkifyProtocols: []string{"https:"}},
+			md:   "ftp://example.com https://example.com",
+			want: "<p>ftp://example.com <a href=\"https://example.com\">https://example.com</a></p>\n",
+		},
+		{
+			name: "Unsafe",
+			opts: MarkdownOptions{Unsafe: true},
+			md:   "<b>d</b>",
+			want: "<p><b>d</b></p>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureMarkdown(tt.opts)
+			defer ConfigureMarkdown(MarkdownOptions{})
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateHTML_MarkdownOptions(t *testing.T) {
+	ConfigureMarkdown(MarkdownOptions{Footnote: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	body, err := ParseMarkdown("a[^1]\n\n[^1]: b").HTML()
+	if err != nil {
+		t.Errorf("Document.HTML() error = %v", err)
+		return
+	}
+	got := CreateHTML(BODY, "", body, "", "", "", "", "", "")
+	for _, want := range []string{
+		`<a href="#fn:1" class="footnote-ref" role="doc-noteref"`,
+		`<div class="footnotes" role="doc-endnotes">`,
+		`<a href="#fnref:1" class="footnote-backref" role="doc-backlink"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CreateHTML() = %v, want %v", got, want)
+		}
+	}
+
+	body = `<div class="box" style="color: red" data-x="1" onclick="alert(1)">a</div><script>alert(1)</script>`
+	got = CreateHTML(BODY, "", body, "", "", "", "", "", "")
+	if want := `<div>a</div>`; got != want {
+		t.Errorf("CreateHTML() = %v, want %v", got, want)
+	}
+	ConfigureMarkdown(MarkdownOptions{Unsafe: true})
+	got = CreateHTML(BODY, "", body, "", "", "", "", "", "")
+	if want := `<div class="box" style="color: red" data-x="1">a</div>`; got != want {
+		t.Errorf("CreateHTML() = %v, want %v", got, want)
+	}
+}
+
+func TestParseDocument_InvalidUTF8(t *testing.T) {
+	// goldmark panics when it renders invalid UTF-8 with the CJK extension.
+	ConfigureMarkdown(MarkdownOptions{CJK: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	_, err := ParseDocument("a.md", []byte("{}\n---\n# T\n\n\x98\n0"))
+	if err == nil || err.Error() != "a.md:5: invalid UTF-8" {
+		t.Errorf("ParseDocument() error = %v, want %v", err, "a.md:5: invalid UTF-8")
+	}
+
+	doc, err := ParseDocument("a.md", []byte("{}\n---\n# T\n\n漢字\n0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.HTML(); err != nil {
+		t.Errorf("Document.HTML() error = %v", err)
+	}
+}
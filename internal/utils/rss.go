@@ -21,8 +21,10 @@ const (
     <title>%s</title>
     <pubDate>%s</pubDate>
     <link>%s</link>
-    <guid isPermaLink="true">%s</guid>
+    <guid isPermaLink="true">%s</guid>%s
   </item>`
+	ITEM_DESCRIPTION_TEMPLATE = `
+    <description>%s</description>`
 	RSS_TEMPLATE = `
 <rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
@@ -102,17 +104,30 @@ func CreateRssFileTask(pages []*Page, tz, outputDir, baseURL, title, description
 		sortedPages := NewSortedLimitedArray(pages, MAX_RSS_ITEMS, compareFn)
 
 		var sb strings.Builder
+		// lastBuildDateは項目の最新の更新日にする
+		lastBuildDate := time.Time{}
 		for _, page := range sortedPages.Items {
 			pubDate, err := time.Parse(DateTime, page.Meta.Date)
 			if err != nil {
 				return errors.WithStack(err)
 			}
-			sb.WriteString(fmt.Sprintf(ITEM_TEMPLATE, page.Title, pubDate.UTC().In(location).Format(time.RFC1123), page.URL, page.URL))
+			itemDescription := ""
+			if page.Description != "" {
+				itemDescription = fmt.Sprintf(ITEM_DESCRIPTION_TEMPLATE, page.Description)
+			}
+			sb.WriteString(fmt.Sprintf(ITEM_TEMPLATE, page.Title, pubDate.UTC().In(location).Format(time.RFC1123), page.URL, page.URL, itemDescription))
+			if page.Updated.After(lastBuildDate) {
+				lastBuildDate = page.Updated
+			}
 		}
 
 		items := sb.String()
-		pubDate := time.Now().UTC().In(location).Format(time.RFC1123)
-		rss := fmt.Sprintf(RSS_TEMPLATE, title, description, baseURL, baseURL, pubDate, pubDate, items)
+		now := time.Now()
+		if lastBuildDate.IsZero() {
+			lastBuildDate = now
+		}
+		pubDate := now.UTC().In(location).Format(time.RFC1123)
+		rss := fmt.Sprintf(RSS_TEMPLATE, title, description, baseURL, baseURL, pubDate, lastBuildDate.UTC().In(location).Format(time.RFC1123), items)
 		rss = strings.TrimSpace(rss)
 		rssFileName := filepath.Join(outputDir, RSS_FILE_NAME)
 		err = os.WriteFile(rssFileName, []byte(rss), 0644)
@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSortedLimitedArray_Push(t *testing.T) {
@@ -40,3 +44,53 @@ func TestSortedLimitedArray_Push(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateRssFileTask_GitHistory(t *testing.T) {
+	categoryOrders := map[string]int{"Go": 0}
+	committed := &Document{
+		FileName: "src/a.md",
+		Title:    "A",
+		PageMeta: PageMeta{Category: "Go"},
+		History: &GitHistory{
+			Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Updated: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	uncommitted := &Document{FileName: "src/b.md", Title: "B", PageMeta: PageMeta{Category: "Go"}}
+	pages := []*Page{}
+	for _, doc := range []*Document{committed, uncommitted} {
+		page, err := CreatePageData(doc, "src", "https://example.com", categoryOrders)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page)
+	}
+
+	outputDir := t.TempDir()
+	if err := CreateRssFileTask(pages, "UTC", outputDir, "https://example.com", "T", "D")(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, RSS_FILE_NAME))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rss := string(content)
+	// The date of the first commit is used for pubDate of the page without a date.
+	if want := "<pubDate>Mon, 01 Jan 2024 00:00:00 UTC</pubDate>"; !strings.Contains(rss, want) {
+		t.Errorf("CreateRssFileTask() = %v, want %v", rss, want)
+	}
+	if strings.Contains(rss, "https://example.com/b.html") {
+		t.Errorf("CreateRssFileTask() = %v, want no item of the page without a date", rss)
+	}
+	// The date of the last commit of the newest page is used for lastBuildDate.
+	if want := "<lastBuildDate>Fri, 01 Mar 2024 00:00:00 UTC</lastBuildDate>"; !strings.Contains(rss, want) {
+		t.Errorf("CreateRssFileTask() = %v, want %v", rss, want)
+	}
+	if want := "<description>D</description>"; !strings.Contains(rss, want) {
+		t.Errorf("CreateRssFileTask() = %v, want %v", rss, want)
+	}
+	// atom:updated is not an element of an RSS 2.0 item.
+	if strings.Contains(rss, "atom:updated") {
+		t.Errorf("CreateRssFileTask() = %v, want no atom:updated", rss)
+	}
+}
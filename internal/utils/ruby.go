@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindRuby is the NodeKind of Ruby.
+var KindRuby = ast.NewNodeKind("Ruby")
+
+// Ruby is the base text annotated with its readings such as {漢字|かんじ} or ｜漢字《かんじ》.
+// Its child is the base text, so that the base text is kept in the plain text of the document.
+type Ruby struct {
+	ast.BaseInline
+	// Readings holds the readings. If it has a reading for each character of the base text, they annotate the characters one by one.
+	Readings []string
+}
+
+func (n *Ruby) Kind() ast.NodeKind {
+	return KindRuby
+}
+
+func (n *Ruby) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Readings": strings.Join(n.Readings, "|")}, nil)
+}
+
+// rubyParser parses {base|reading}.
+// {base|reading1|reading2} annotates each character of the base text with its reading if the numbers of them are the same.
+// The base text or a reading must contain Japanese characters, so that {GET|POST} in technical text is left as it is.
+type rubyParser struct{}
+
+// hasJapanese determines if a text contains kanji, hiragana or katakana.
+func hasJapanese(text string) bool {
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー' {
+			return true
+		}
+	}
+	return false
+}
+
+func (p rubyParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p rubyParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	end := bytes.IndexAny(line[1:], "{}\n") + 1
+	if end <= 0 || line[end] != '}' {
+		return nil
+	}
+	parts := strings.Split(string(line[1:end]), "|")
+	if len(parts) < 2 || strings.TrimSpace(parts[0]) == "" {
+		return nil
+	}
+	for _, reading := range parts[1:] {
+		if strings.TrimSpace(reading) == "" {
+			return nil
+		}
+	}
+	if !hasJapanese(string(line[1:end])) {
+		return nil
+	}
+	node := &Ruby{Readings: parts[1:]}
+	node.AppendChild(node, ast.NewTextSegment(text.NewSegment(segment.Start+1, segment.Start+1+len(parts[0]))))
+	block.Advance(end + 1)
+	return node
+}
+
+// aozoraRubyRe matches the ruby of Aozora Bunko such as ｜東京《とうきょう》 or 漢字《かんじ》.
+// Without ｜, the reading annotates the kanji just before it.
+var aozoraRubyRe = regexp.MustCompile(`｜([^｜《》\n]+)《([^《》\n]+)》|([\p{Han}々〆ヶ]+)《([^《》\n]+)》`)
+
+// rubyTransformer converts the ruby of Aozora Bunko in texts into Ruby nodes.
+// Unlike {base|reading}, it is found in texts after they are parsed because goldmark runs inline parsers only at ASCII punctuations and spaces.
+type rubyTransformer struct{}
+
+func (t rubyTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	texts := []*ast.Text{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.CodeSpan, *MathInline, *Ruby:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if !n.IsRaw() {
+				texts = append(texts, n)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, t := range texts {
+		start := t.Segment.Start
+		for _, m := range aozoraRubyRe.FindAllSubmatchIndex(t.Segment.Value(source), -1) {
+			base, reading := m[2:4], m[4:6]
+			if base[0] < 0 {
+				base, reading = m[6:8], m[8:10]
+			}
+			if t.Segment.Start+m[0] > start {
+				t.Parent().InsertBefore(t.Parent(), t, ast.NewTextSegment(text.NewSegment(start, t.Segment.Start+m[0])))
+			}
+			node := &Ruby{Readings: []string{string(source[t.Segment.Start+reading[0] : t.Segment.Start+reading[1]])}}
+			node.AppendChild(node, ast.NewTextSegment(text.NewSegment(t.Segment.Start+base[0], t.Segment.Start+base[1])))
+			t.Parent().InsertBefore(t.Parent(), t, node)
+			start = t.Segment.Start + m[1]
+		}
+		// The rest of the text keeps the line break after it.
+		t.Segment = t.Segment.WithStart(start)
+	}
+}
+
+// writeRuby writes a pair of the base text and its reading.
+// The parentheses in <rp> are shown by the browsers that do not support ruby.
+func writeRuby(w util.BufWriter, base, reading string) error {
+	_, err := w.WriteString("<rb>" + string(util.EscapeHTML([]byte(base))) + "</rb><rp>（</rp><rt>" +
+		string(util.EscapeHTML([]byte(reading))) + "</rt><rp>）</rp>")
+	return err
+}
+
+func (r customRenderer) renderRuby(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*Ruby)
+	base := string(n.FirstChild().(*ast.Text).Segment.Value(source))
+	if _, err := w.WriteString("<ruby>"); err != nil {
+		return 0, err
+	}
+	if len(n.Readings) > 1 && len(n.Readings) == utf8.RuneCountInString(base) {
+		for i, c := range []rune(base) {
+			if err := writeRuby(w, string(c), n.Readings[i]); err != nil {
+				return 0, err
+			}
+		}
+	} else if err := writeRuby(w, base, strings.Join(n.Readings, "")); err != nil {
+		return 0, err
+	}
+	_, err := w.WriteString("</ruby>")
+	return ast.WalkSkipChildren, err
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderRuby(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{
+			name: "Braces",
+			md:   "{漢字|かんじ}を読む",
+			want: "<p><ruby><rb>漢字</rb><rp>（</rp><rt>かんじ</rt><rp>）</rp></ruby>を読む</p>\n",
+		},
+		{
+			name: "Each character",
+			md:   "{漢字|かん|じ}",
+			want: "<p><ruby><rb>漢</rb><rp>（</rp><rt>かん</rt><rp>）</rp><rb>字</rb><rp>（</rp><rt>じ</rt><rp>）</rp></ruby></p>\n",
+		},
+		{
+			name: "Aozora Bunko",
+			md:   "これは｜東京《とうきょう》です。",
+			want: "<p>これは<ruby><rb>東京</rb><rp>（</rp><rt>とうきょう</rt><rp>）</rp></ruby>です。</p>\n",
+		},
+		{
+			name: "Aozora Bunko without bar",
+			md:   "ひらがなと漢字《かんじ》、*強調*と日本《にほん》",
+			want: "<p>ひらがなと<ruby><rb>漢字</rb><rp>（</rp><rt>かんじ</rt><rp>）</rp></ruby>、<em>強調</em>と<ruby><rb>日本</rb><rp>（</rp><rt>にほん</rt><rp>）</rp></ruby></p>\n",
+		},
+		{
+			name: "Escaped",
+			md:   "{<a>|<かん>}",
+			want: "<p><ruby><rb>&lt;a&gt;</rb><rp>（</rp><rt>&lt;かん&gt;</rt><rp>）</rp></ruby></p>\n",
+		},
+		{
+			name: "Reading of Latin letters",
+			md:   "{Tokyo|とうきょう}",
+			want: "<p><ruby><rb>Tokyo</rb><rp>（</rp><rt>とうきょう</rt><rp>）</rp></ruby></p>\n",
+		},
+		{
+			name: "ASCII only",
+			md:   "{GET|POST} を使う。{a|b|c}",
+			want: "<p>{GET|POST} を使う。{a|b|c}</p>\n",
+		},
+		{
+			name: "Backslash",
+			md:   "\\{漢字|かんじ}",
+			want: "<p>{漢字|かんじ}</p>\n",
+		},
+		{
+			name: "Not ruby",
+			md:   "{a} {|a} {a|} {a|b ｜a ｜《a》 `{a|b}`",
+			want: "<p>{a} {|a} {a|} {a|b ｜a ｜《a》 <code>{a|b}</code></p>\n",
+		},
+	}
+	ConfigureMarkdown(MarkdownOptions{Ruby: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateHTML_Ruby(t *testing.T) {
+	ConfigureMarkdown(MarkdownOptions{Ruby: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	doc, err := ParseDocument("a.md", []byte("{}\n---\n# {漢字|かんじ}\n\n｜東京《とうきょう》に行く"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "漢字" || doc.Text != "東京に行く" {
+		t.Errorf("Document.Title = %v, Document.Text = %v", doc.Title, doc.Text)
+	}
+	body, err := doc.HTML()
+	if err != nil {
+		t.Errorf("Document.HTML() error = %v", err)
+		return
+	}
+	got := CreateHTML(BODY, "", body, "", "", "", "", "", "")
+	if want := "<p><ruby><rb>東京</rb><rp>（</rp><rt>とうきょう</rt><rp>）</rp></ruby>に行く</p>"; !strings.Contains(got, want) {
+		t.Errorf("CreateHTML() = %v, want %v", got, want)
+	}
+	if got, want := doc.Description(), "東京に行く"; got != want {
+		t.Errorf("Document.Description() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,436 @@
+package utils
+
+import (
+	"bytes"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	DEFAULT_SHORTCODE_DIR = "shortcodes"
+	// shortcodeInner is the placeholder of the content of a shortcode in the output of its template.
+	shortcodeInner = "<!--shortcode-inner-->"
+)
+
+// Shortcode is a template that a shortcode such as {{< name key="value" >}} is rendered with.
+type Shortcode struct {
+	Name     string
+	Template *template.Template
+	// Paired is true if the shortcode has content and is closed by {{< /name >}}.
+	Paired bool
+	// Positional holds the names of the parameters that are given without names such as {{< youtube "id" >}}.
+	Positional []string
+	// Trusted is true if the output of the template is inserted into the page after the page is sanitized.
+	// The user-defined shortcodes are trusted only if it is enabled explicitly, so that they can emit their own elements and classes.
+	Trusted bool
+}
+
+// ShortcodeContext is the data that the template of a shortcode is executed with.
+type ShortcodeContext struct {
+	Name   string
+	Params map[string]string
+	Args   []string // Args holds the parameters given without names.
+	// Inner is the HTML rendered from the markdown between the opening and closing shortcodes.
+	Inner template.HTML
+}
+
+// Get returns the parameter of the shortcode.
+func (c ShortcodeContext) Get(key string) string {
+	return c.Params[key]
+}
+
+// shortcodeClassPattern matches the classes of the elements that the built-in shortcodes emit.
+// The callout shortcode emits the classes of calloutClassPattern.
+var shortcodeClassPattern = regexp.MustCompile(`^(shortcode-video|tabs)$`)
+
+// youtubePattern matches the URLs of the videos that the youtube shortcode embeds.
+var youtubePattern = regexp.MustCompile(`^https://www\.youtube-nocookie\.com/embed/[\w-]+$`)
+
+var shortcodeFuncs = template.FuncMap{
+	"calloutType":  calloutType,
+	"calloutTitle": calloutTitle,
+}
+
+// usesInner determines if a node of a parsed template refers to .Inner, such as {{ .Inner }}, {{ $.Inner }} or {{ with .Inner }}.
+// Comments and text that contain ".Inner" are not references.
+func usesInner(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Nodes {
+			if usesInner(c) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return usesInner(n.Pipe)
+	case *parse.IfNode:
+		return usesInner(&n.BranchNode)
+	case *parse.RangeNode:
+		return usesInner(&n.BranchNode)
+	case *parse.WithNode:
+		return usesInner(&n.BranchNode)
+	case *parse.BranchNode:
+		return usesInner(n.Pipe) || usesInner(n.List) || usesInner(n.ElseList)
+	case *parse.TemplateNode:
+		return usesInner(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Cmds {
+			if usesInner(c) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, c := range n.Args {
+			if usesInner(c) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == "Inner"
+	case *parse.VariableNode:
+		return len(n.Ident) > 1 && n.Ident[0] == "$" && n.Ident[1] == "Inner"
+	case *parse.ChainNode:
+		return usesInner(n.Node) || len(n.Field) > 0 && n.Field[0] == "Inner"
+	}
+	return false
+}
+
+// newShortcode creates a shortcode from its template.
+// The shortcode is paired if one of the templates refers to .Inner.
+func newShortcode(name, tmpl string, positional ...string) (*Shortcode, error) {
+	t, err := template.New(name).Funcs(shortcodeFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	paired := false
+	for _, defined := range t.Templates() {
+		if defined.Tree != nil && usesInner(defined.Tree.Root) {
+			paired = true
+		}
+	}
+	return &Shortcode{Name: name, Template: t, Paired: paired, Positional: positional}, nil
+}
+
+// builtinShortcodes holds the templates of the built-in shortcodes.
+var builtinShortcodes = func() map[string]*Shortcode {
+	templates := []struct {
+		name       string
+		template   string
+		positional []string
+	}{
+		{
+			name:       "youtube",
+			template:   `<div class="shortcode-video"><iframe src="https://www.youtube-nocookie.com/embed/{{ .Get "id" }}" title="{{ or (.Get "title") "YouTube" }}" allowfullscreen></iframe></div>`,
+			positional: []string{"id"},
+		},
+		{
+			name:       "video",
+			template:   `<video src="{{ .Get "src" }}" controls preload="metadata"{{ with .Get "poster" }} poster="{{ . }}"{{ end }}></video>`,
+			positional: []string{"src"},
+		},
+		{
+			name:       "audio",
+			template:   `<audio src="{{ .Get "src" }}" controls preload="metadata"></audio>`,
+			positional: []string{"src"},
+		},
+		{
+			name:       "figure",
+			template:   `<figure><img loading="lazy" src="{{ .Get "src" }}" alt="{{ or (.Get "alt") (.Get "caption") }}">{{ with .Get "caption" }}<figcaption>{{ . }}</figcaption>{{ end }}</figure>`,
+			positional: []string{"src"},
+		},
+		{
+			name:       "details",
+			template:   `<details{{ if .Get "open" }} open{{ end }}><summary>{{ or (.Get "summary") "Details" }}</summary>{{ .Inner }}</details>`,
+			positional: []string{"summary"},
+		},
+		{
+			name:     "tabs",
+			template: `<div class="tabs">{{ .Inner }}</div>`,
+		},
+		{
+			name:       "tab",
+			template:   `<details class="tab" open><summary>{{ .Get "title" }}</summary>{{ .Inner }}</details>`,
+			positional: []string{"title"},
+		},
+		{
+			name:       "callout",
+			template:   `<div class="callout callout-{{ calloutType (.Get "type") }}"><p class="callout-title">{{ or (.Get "title") (calloutTitle (.Get "type")) }}</p>{{ .Inner }}</div>`,
+			positional: []string{"type"},
+		},
+	}
+	shortcodes := map[string]*Shortcode{}
+	for _, t := range templates {
+		shortcode, err := newShortcode(t.name, t.template, t.positional...)
+		if err != nil {
+			panic(err)
+		}
+		shortcodes[t.name] = shortcode
+	}
+	return shortcodes
+}()
+
+// LoadShortcodes reads the templates of shortcodes such as "dir/name.html".
+// A shortcode is named after its file name. If dir does not exist, it returns no shortcodes.
+// If trusted is true, the output of the templates is not sanitized. Otherwise it is sanitized like the rest of the page.
+func LoadShortcodes(dir string, trusted bool) (map[string]*Shortcode, error) {
+	shortcodes := map[string]*Shortcode{}
+	fileNames, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, fileName := range fileNames {
+		content, err := os.ReadFile(fileName)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		name := strings.TrimSuffix(filepath.Base(fileName), ".html")
+		shortcode, err := newShortcode(name, string(content))
+		if err != nil {
+			return nil, errors.WithMessage(err, fileName)
+		}
+		shortcode.Trusted = trusted
+		shortcodes[name] = shortcode
+	}
+	return shortcodes, nil
+}
+
+var (
+	// shortcodeOpenRe matches a line that consists only of an opening shortcode.
+	shortcodeOpenRe = regexp.MustCompile(`^ {0,3}\{\{<\s*([a-zA-Z][\w-]*)((?:\s+(?:[a-zA-Z][\w-]*=)?"[^"]*")*)\s*>\}\}\s*$`)
+	// shortcodeParamRe matches a parameter of a shortcode such as key="value" or "value".
+	shortcodeParamRe = regexp.MustCompile(`(?:([a-zA-Z][\w-]*)=)?"([^"]*)"`)
+	// shortcodeCloseRe matches a line that consists only of a closing shortcode.
+	shortcodeCloseRe = regexp.MustCompile(`^ {0,3}\{\{<\s*/([a-zA-Z][\w-]*)\s*>\}\}\s*$`)
+)
+
+// KindShortcode is the NodeKind of ShortcodeBlock.
+var KindShortcode = ast.NewNodeKind("Shortcode")
+
+// ShortcodeBlock is a block that is rendered with the template of a shortcode.
+// The children of a paired shortcode are the content between the opening and closing shortcodes.
+type ShortcodeBlock struct {
+	ast.BaseBlock
+	Shortcode *Shortcode
+	Params    map[string]string
+	Args      []string
+
+	open bool // open is true while the lines of the block are parsed.
+}
+
+func (n *ShortcodeBlock) Kind() ast.NodeKind {
+	return KindShortcode
+}
+
+func (n *ShortcodeBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.Shortcode.Name}, nil)
+}
+
+// advanceLine advances the reader to the end of the line except the newline.
+func advanceLine(reader text.Reader, line []byte, segment text.Segment) {
+	newline := 0
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		newline = 1
+	}
+	reader.Advance(segment.Len() - newline)
+}
+
+// shortcodeParser parses the shortcodes that are written on their own lines.
+type shortcodeParser struct {
+	shortcodes map[string]*Shortcode
+}
+
+func (p *shortcodeParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *shortcodeParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	m := shortcodeOpenRe.FindSubmatch(line)
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+	shortcode, exists := p.shortcodes[string(m[1])]
+	if !exists {
+		return nil, parser.NoChildren
+	}
+	node := &ShortcodeBlock{Shortcode: shortcode, Params: map[string]string{}, Args: []string{}}
+	for _, param := range shortcodeParamRe.FindAllSubmatch(m[2], -1) {
+		if len(param[1]) > 0 {
+			node.Params[string(param[1])] = string(param[2])
+			continue
+		}
+		if len(node.Args) < len(shortcode.Positional) {
+			name := shortcode.Positional[len(node.Args)]
+			if _, exists := node.Params[name]; !exists {
+				node.Params[name] = string(param[2])
+			}
+		}
+		node.Args = append(node.Args, string(param[2]))
+	}
+	advanceLine(reader, line, segment)
+	if shortcode.Paired {
+		node.open = true
+		return node, parser.HasChildren
+	}
+	return node, parser.NoChildren
+}
+
+func (p *shortcodeParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*ShortcodeBlock)
+	if !n.Shortcode.Paired {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if m := shortcodeCloseRe.FindSubmatch(line); m != nil && string(m[1]) == n.Shortcode.Name && !hasOpenShortcode(n, n.Shortcode.Name) {
+		advanceLine(reader, line, segment)
+		return parser.Close
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+// hasOpenShortcode determines if a shortcode with the name is open in a block.
+// The open blocks are the last children, so that the closing shortcode of a nested shortcode with the same name closes the nested one.
+func hasOpenShortcode(node ast.Node, name string) bool {
+	for c := node.LastChild(); c != nil; c = c.LastChild() {
+		if n, ok := c.(*ShortcodeBlock); ok && n.open && n.Shortcode.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *shortcodeParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	node.(*ShortcodeBlock).open = false
+}
+
+func (p *shortcodeParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *shortcodeParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// executeShortcode executes the template of a shortcode, and returns the output before and after the content.
+// It is an error if the template outputs the content more than once.
+func executeShortcode(n *ShortcodeBlock) (string, string, error) {
+	var buf bytes.Buffer
+	err := n.Shortcode.Template.Execute(&buf, ShortcodeContext{
+		Name:   n.Shortcode.Name,
+		Params: n.Params,
+		Args:   n.Args,
+		Inner:  template.HTML(shortcodeInner),
+	})
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+	if strings.Count(buf.String(), shortcodeInner) > 1 {
+		return "", "", errors.Errorf("shortcode %s outputs .Inner more than once", n.Shortcode.Name)
+	}
+	opening, closing, _ := strings.Cut(buf.String(), shortcodeInner)
+	return opening, closing, nil
+}
+
+// prepareShortcodes executes the templates of the trusted shortcodes when the document is parsed.
+// Their output is rendered as placeholders, and is inserted into the page by insertShortcodes after the page is sanitized,
+// because the policy of the page only allows the elements and classes of the built-in shortcodes.
+// The content of a paired shortcode is rendered and sanitized like the rest of the page.
+func (d *Document) prepareShortcodes() error {
+	return ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		block, ok := n.(*ShortcodeBlock)
+		if !ok || !entering || !block.Shortcode.Trusted {
+			return ast.WalkContinue, nil
+		}
+		opening, closing, err := executeShortcode(block)
+		if err != nil {
+			return ast.WalkStop, errors.Errorf("%s:%d: %s", d.FileName, d.Line(block), err)
+		}
+		for _, output := range []struct{ name, html string }{{"opening", opening}, {"closing", closing}} {
+			placeholder := ""
+			if output.html != "" {
+				placeholder = trustedPlaceholder("SHORTCODE", output.html)
+				if d.shortcodes == nil {
+					d.shortcodes = map[string]string{}
+				}
+				d.shortcodes[placeholder] = output.html
+			}
+			block.SetAttributeString(output.name, []byte(placeholder))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// insertShortcodes replaces the placeholders of the trusted shortcodes in the sanitized page with their output.
+func (d *Document) insertShortcodes(html string) string {
+	for placeholder, output := range d.shortcodes {
+		html = strings.ReplaceAll(html, placeholder, output)
+	}
+	return html
+}
+
+// shortcodeOutput returns the output of a shortcode before and after the content.
+// The trusted shortcodes prepared by prepareShortcodes are rendered as their placeholders.
+func shortcodeOutput(n *ShortcodeBlock) (string, string, error) {
+	opening, prepared := n.AttributeString("opening")
+	if !prepared {
+		return executeShortcode(n)
+	}
+	closing, _ := n.AttributeString("closing")
+	return string(opening.([]byte)), string(closing.([]byte)), nil
+}
+
+// renderShortcode executes the template of a shortcode.
+// The output before the content is written when entering the block, and the rest is written when leaving it.
+// The template is executed again when leaving the block, so that nothing is stored in the node while it is rendered.
+func (r customRenderer) renderShortcode(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ShortcodeBlock)
+	opening, closing, err := shortcodeOutput(n)
+	if err != nil {
+		return 0, err
+	}
+	if !entering {
+		_, err := w.WriteString(closing + "\n")
+		return ast.WalkContinue, err
+	}
+	if _, err := w.WriteString(opening); err != nil {
+		return 0, err
+	}
+	if !n.Shortcode.Paired {
+		return ast.WalkSkipChildren, nil
+	}
+	_, err = w.WriteString("\n")
+	return ast.WalkContinue, err
+}
+
+// shortcodes returns the built-in shortcodes and the user-defined shortcodes.
+// A user-defined shortcode overrides the built-in shortcode with the same name.
+func shortcodes(userShortcodes map[string]*Shortcode) map[string]*Shortcode {
+	res := map[string]*Shortcode{}
+	for name, shortcode := range builtinShortcodes {
+		res[name] = shortcode
+	}
+	for name, shortcode := range userShortcodes {
+		res[name] = shortcode
+	}
+	return res
+}
@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderShortcode(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{
+			name: "YouTube",
+			md:   "{{< youtube \"abc-123\" >}}\n\ntext",
+			want: `<div class="shortcode-video"><iframe src="https://www.youtube-nocookie.com/embed/abc-123" title="YouTube" allowfullscreen></iframe></div>` + "\n<p>text</p>\n",
+		},
+		{
+			name: "Figure",
+			md:   "{{< figure src=\"a.png\" caption=\"A <b>\" >}}",
+			want: `<figure><img loading="lazy" src="a.png" alt="A &lt;b&gt;"><figcaption>A &lt;b&gt;</figcaption></figure>` + "\n",
+		},
+		{
+			name: "Callout",
+			md:   "{{< callout \"warning\" >}}\n**Be careful**\n{{< /callout >}}\n\ntext",
+			want: `<div class="callout callout-warning"><p class="callout-title">Warning</p>` + "\n<p><strong>Be careful</strong></p>\n</div>\n<p>text</p>\n",
+		},
+		{
+			name: "Tabs",
+			md:   "{{< tabs >}}\n{{< tab \"Go\" >}}\n- a\n{{< /tab >}}\n{{< tab title=\"Rust\" >}}\nb\n{{< /tab >}}\n{{< /tabs >}}",
+			want: `<div class="tabs">` + "\n" +
+				`<details class="tab" open><summary>Go</summary>` + "\n<ul>\n<li>a</li>\n</ul>\n</details>\n" +
+				`<details class="tab" open><summary>Rust</summary>` + "\n<p>b</p>\n</details>\n" +
+				"</div>\n",
+		},
+		{
+			name: "Nested",
+			md:   "{{< details \"A\" >}}\n{{< details \"B\" >}}\nb\n{{< /details >}}\na\n{{< /details >}}",
+			want: "<details><summary>A</summary>\n<details><summary>B</summary>\n<p>b</p>\n</details>\n<p>a</p>\n</details>\n",
+		},
+		{
+			name: "Unknown",
+			md:   "{{< unknown >}}",
+			want: "<p>{{&lt; unknown &gt;}}</p>\n",
+		},
+		{
+			name: "In code block",
+			md:   "```\n{{< youtube \"abc\" >}}\n```",
+			want: "<pre><code>{{&lt; youtube &quot;abc&quot; &gt;}}\n</code></pre>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMarkdown(tt.md).HTML()
+			if err != nil {
+				t.Errorf("Document.HTML() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.HTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateHTML_Shortcodes(t *testing.T) {
+	md := `{{< youtube "abc" >}}
+
+{{< video "a.mp4" >}}
+
+{{< audio "a.mp3" >}}
+
+{{< details "More" open="true" >}}
+a
+{{< /details >}}
+
+{{< tabs >}}
+{{< tab "Go" >}}
+b
+{{< /tab >}}
+{{< /tabs >}}
+
+{{< callout "tip" >}}
+c
+{{< /callout >}}`
+	body, err := ParseMarkdown(md).HTML()
+	if err != nil {
+		t.Errorf("Document.HTML() error = %v", err)
+		return
+	}
+	got := CreateHTML(BODY, "", body, "", "", "", "", "", "")
+	for _, want := range []string{
+		`<iframe src="https://www.youtube-nocookie.com/embed/abc" title="YouTube" allowfullscreen=""></iframe>`,
+		`<video src="a.mp4" controls="" preload="metadata"></video>`,
+		`<audio src="a.mp3" controls="" preload="metadata"></audio>`,
+		`<details open=""><summary>More</summary>`,
+		`<div class="tabs">`,
+		`<details class="tab" open=""><summary>Go</summary>`,
+		`<div class="callout callout-tip"><p class="callout-title">Tip</p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CreateHTML() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestLoadShortcodes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "note.html"), []byte(`<div class="callout callout-note">{{ .Get "0" }}{{ index .Args 0 }}{{ .Inner }}</div>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shortcodes, err := LoadShortcodes(dir, true)
+	if err != nil {
+		t.Errorf("LoadShortcodes() error = %v", err)
+		return
+	}
+	if !shortcodes["note"].Paired {
+		t.Errorf("Shortcode.Paired = false, want true")
+	}
+
+	ConfigureMarkdown(MarkdownOptions{Shortcodes: shortcodes})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	got, err := ParseMarkdown("{{< note \"A\" >}}\nb\n{{< /note >}}").HTML()
+	if err != nil {
+		t.Errorf("Document.HTML() error = %v", err)
+		return
+	}
+	want := "<div class=\"callout callout-note\">A\n<p>b</p>\n</div>\n"
+	if got != want {
+		t.Errorf("Document.HTML() = %v, want %v", got, want)
+	}
+
+	shortcodes, err = LoadShortcodes(filepath.Join(dir, "missing"), true)
+	if err != nil || len(shortcodes) != 0 {
+		t.Errorf("LoadShortcodes() = %v, %v, want no shortcodes", shortcodes, err)
+	}
+}
+
+func TestNewShortcode_Paired(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     bool
+	}{
+		{name: "Inner", template: `<div>{{ .Inner }}</div>`, want: true},
+		{name: "Variable", template: `{{ range .Args }}{{ $.Inner }}{{ end }}`, want: true},
+		{name: "With", template: `{{ with .Inner }}<div>{{ . }}</div>{{ end }}`, want: true},
+		{name: "Defined template", template: `{{ define "body" }}{{ .Inner }}{{ end }}<div>{{ template "body" . }}</div>`, want: true},
+		{name: "Comment", template: `{{/* .Inner is not used */}}<div></div>`, want: false},
+		{name: "String", template: `<div title="{{ ".Inner" }}">.Inner</div>`, want: false},
+		{name: "Other field", template: `<div>{{ .Get "Inner" }}{{ .Name }}</div>`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortcode, err := newShortcode("test", tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shortcode.Paired != tt.want {
+				t.Errorf("Shortcode.Paired = %v, want %v", shortcode.Paired, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderShortcode_RenderTwice(t *testing.T) {
+	doc := ParseMarkdown("{{< details \"More\" >}}\n{{< callout \"tip\" >}}\na\n{{< /callout >}}\n{{< /details >}}")
+	first, err := doc.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := doc.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("Document.HTML() = %v, want %v", second, first)
+	}
+}
+
+func TestCreatePage_TrustedShortcodes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "note.html"), []byte(`<div class="my-note" data-kind="info"><aside>{{ .Get "title" }}</aside>{{ .Inner }}</div>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shortcodes, err := LoadShortcodes(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ConfigureMarkdown(MarkdownOptions{Shortcodes: shortcodes, Unsafe: true})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	doc, err := ParseDocument("a.md", []byte("{}\n---\n# T\n\n{{< note title=\"<b>\" >}}\n<span onclick=\"alert(1)\">b</span>\n{{< /note >}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CreatePage(BODY, doc, "", "", "", "", TOCOptions{MinLevel: DEFAULT_TOC_MIN, MaxLevel: DEFAULT_TOC_MAX})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The output of the template is kept, and the content is sanitized.
+	if want := `<div class="my-note" data-kind="info"><aside>&lt;b&gt;</aside>`; !strings.Contains(got, want) {
+		t.Errorf("CreatePage() = %v, want %v", got, want)
+	}
+	if want := "<p><span>b</span></p>\n</div>"; !strings.Contains(got, want) {
+		t.Errorf("CreatePage() = %v, want %v", got, want)
+	}
+	if strings.Contains(got, "onclick") || strings.Contains(got, "__SHORTCODE_") {
+		t.Errorf("CreatePage() = %v, want the sanitized content without placeholders", got)
+	}
+}
+
+func TestCreatePage_UntrustedShortcodes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "note.html"), []byte(`<div class="my-note"><aside onclick="alert(1)">{{ .Get "title" }}</aside>{{ .Inner }}</div>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shortcodes, err := LoadShortcodes(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ConfigureMarkdown(MarkdownOptions{Shortcodes: shortcodes})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	doc, err := ParseDocument("a.md", []byte("{}\n---\n# T\n\n{{< note title=\"a\" >}}\nb\n{{< /note >}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CreatePage(BODY, doc, "", "", "", "", TOCOptions{MinLevel: DEFAULT_TOC_MIN, MaxLevel: DEFAULT_TOC_MAX})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The output of the template is sanitized like the rest of the page.
+	if want := "<div><aside>a</aside>\n<p>b</p>\n</div>"; !strings.Contains(got, want) {
+		t.Errorf("CreatePage() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderShortcode_InnerTwice(t *testing.T) {
+	shortcode, err := newShortcode("twice", `<div>{{ .Inner }}{{ .Inner }}</div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ConfigureMarkdown(MarkdownOptions{Shortcodes: map[string]*Shortcode{"twice": shortcode}})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	if _, err := ParseMarkdown("{{< twice >}}\na\n{{< /twice >}}").HTML(); err == nil {
+		t.Errorf("Document.HTML() error = nil, want an error")
+	}
+}
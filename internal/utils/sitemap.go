@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+)
+
+const (
+	SITEMAP_FILE_NAME = "sitemap.xml"
+	SITEMAP_TEMPLATE  = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">%s
+</urlset>
+`
+	SITEMAP_URL_TEMPLATE = `
+  <url>
+    <loc>%s</loc>%s
+  </url>`
+	SITEMAP_LASTMOD_TEMPLATE = `
+    <lastmod>%s</lastmod>`
+)
+
+// SitemapURL is a page in the sitemap.
+type SitemapURL struct {
+	Loc string
+	// LastMod is the date the page was last modified. If it is the zero time, lastmod is omitted.
+	LastMod time.Time
+}
+
+// CreateSitemapURLs returns the URLs of the pages of the documents and the index pages of the languages.
+// The last modified date of an index page is the latest date of the pages in its language.
+func CreateSitemapURLs(docs []*Document, sourceDir, baseURL string, indexes bool) []SitemapURL {
+	urls := []SitemapURL{}
+	lastMods := map[string]time.Time{}
+	for _, doc := range docs {
+		updated := doc.Updated()
+		urls = append(urls, SitemapURL{Loc: doc.URL(sourceDir, baseURL), LastMod: updated})
+		lang := doc.Language(sourceDir)
+		if updated.After(lastMods[lang]) {
+			lastMods[lang] = updated
+		}
+	}
+	if indexes {
+		langs := []string{""}
+		if len(markdownOptions.Languages) > 0 {
+			langs = []string{}
+			for _, l := range markdownOptions.Languages {
+				langs = append(langs, l.Code)
+			}
+		}
+		for _, lang := range langs {
+			urls = append(urls, SitemapURL{Loc: baseURL + LanguagePrefix(lang) + "/", LastMod: lastMods[lang]})
+		}
+	}
+	sort.Slice(urls, func(i, j int) bool {
+		return urls[i].Loc < urls[j].Loc
+	})
+	return urls
+}
+
+// CreateSitemap generates sitemap.xml from the URLs.
+func CreateSitemap(urls []SitemapURL) string {
+	var sb strings.Builder
+	for _, url := range urls {
+		lastMod := ""
+		if !url.LastMod.IsZero() {
+			lastMod = fmt.Sprintf(SITEMAP_LASTMOD_TEMPLATE, url.LastMod.UTC().Format(time.RFC3339))
+		}
+		sb.WriteString(fmt.Sprintf(SITEMAP_URL_TEMPLATE, html.EscapeString(url.Loc), lastMod))
+	}
+	return fmt.Sprintf(SITEMAP_TEMPLATE, sb.String())
+}
+
+// CreateSitemapFileTask returns a task that writes sitemap.xml into outputDir.
+func CreateSitemapFileTask(urls []SitemapURL, outputDir string) func() error {
+	return func() error {
+		sitemapFileName := filepath.Join(outputDir, SITEMAP_FILE_NAME)
+		return errors.WithStack(os.WriteFile(sitemapFileName, []byte(CreateSitemap(urls)), 0644))
+	}
+}
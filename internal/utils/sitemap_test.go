@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreateSitemap(t *testing.T) {
+	ConfigureMarkdown(MarkdownOptions{Languages: ParseLanguages("ja,en")})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	docs := []*Document{
+		{FileName: "src/a.md", History: &GitHistory{Updated: time.Date(2024, 3, 1, 9, 0, 0, 0, time.FixedZone("", 9*60*60))}},
+		{FileName: "src/a.en.md", PageMeta: PageMeta{Date: "2024-01-01 00:00"}},
+		{FileName: "src/b&c.md"},
+	}
+	urls := CreateSitemapURLs(docs, "src", "https://example.com", true)
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/</loc>
+    <lastmod>2024-03-01T00:00:00Z</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/a.html</loc>
+    <lastmod>2024-03-01T00:00:00Z</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/b&amp;c.html</loc>
+  </url>
+  <url>
+    <loc>https://example.com/en/</loc>
+    <lastmod>2024-01-01T00:00:00Z</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/en/a.html</loc>
+    <lastmod>2024-01-01T00:00:00Z</lastmod>
+  </url>
+</urlset>
+`
+	if got := CreateSitemap(urls); got != want {
+		t.Errorf("CreateSitemap() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PATH_PATTERN is replaced with the path of the markdown file in the URL patterns of SourceLinks.
+const PATH_PATTERN = "{path}"
+
+// REF_PATTERN is replaced with the git ref of the markdown file in the URL patterns of SourceLinks.
+const REF_PATTERN = "{ref}"
+
+// DEFAULT_REF replaces REF_PATTERN for the markdown files in the working tree.
+const DEFAULT_REF = "HEAD"
+
+// SourceLinks holds the URL patterns of the links to the markdown file of a page in the repository
+// such as "https://github.com/org/repo/edit/main/{path}". An empty pattern is not linked.
+type SourceLinks struct {
+	Edit    string
+	Source  string
+	History string
+	// Ref is the git ref the markdown files are read from such as "v1.0.0", or "" for the working tree.
+	// If it is set, Edit and the patterns without REF_PATTERN are not linked because they point to another ref.
+	Ref string
+}
+
+// RepositoryPath returns the path of a file relative to root, the root of the git repository, such as "src/guide/page.md".
+// If root is "" or the file is not in root, it returns the path as it is.
+func RepositoryPath(root, fileName string) string {
+	if root != "" {
+		if path, ok := pathInRepository(root, fileName); ok {
+			return path
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(fileName))
+}
+
+// escapePath escapes each segment of a slash separated path.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// url returns the URL of a pattern for a path, or "" if the pattern or the path is empty.
+// Each segment of the path and the ref is escaped.
+func (l SourceLinks) url(pattern, path string) string {
+	if pattern == "" || path == "" {
+		return ""
+	}
+	ref := DEFAULT_REF
+	if l.Ref != "" {
+		if !strings.Contains(pattern, REF_PATTERN) {
+			return ""
+		}
+		ref = l.Ref
+	}
+	pattern = strings.ReplaceAll(pattern, REF_PATTERN, escapePath(ref))
+	return strings.ReplaceAll(pattern, PATH_PATTERN, escapePath(path))
+}
+
+// link returns the <a> element that links to the URL of a pattern for a path, or "" if the URL is empty.
+func (l SourceLinks) link(pattern, path, class, label string) string {
+	url := l.url(pattern, path)
+	if url == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<a href="%s" class="%s">%s</a>`, html.EscapeString(url), class, label)
+}
+
+// Layout replaces __EDIT_LINK__, __SOURCE_LINK__ and __HISTORY_LINK__ in a layout with the links to the markdown file at path in the repository.
+// If path is "" or the URL pattern of a link is empty, its placeholder is replaced with "", so that the layout has no empty link.
+func (l SourceLinks) Layout(layout, path string) string {
+	edit := l.Edit
+	// gitのrefのファイルは編集できない
+	if l.Ref != "" {
+		edit = ""
+	}
+	layout = strings.ReplaceAll(layout, EDIT_LINK, l.link(edit, path, "edit-link", "Edit this page"))
+	layout = strings.ReplaceAll(layout, SOURCE_LINK, l.link(l.Source, path, "source-link", "Source"))
+	return strings.ReplaceAll(layout, HISTORY_LINK, l.link(l.History, path, "history-link", "History"))
+}
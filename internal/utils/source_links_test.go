@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryPath(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs", "src"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "src", "page.md"), []byte("# Page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "src")
+	if err := os.Symlink(filepath.Join(root, "docs", "src"), link); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		root     string
+		fileName string
+		want     string
+	}{
+		{"in repository", root, filepath.Join(root, "docs", "src", "guide", "page.md"), "docs/src/guide/page.md"},
+		// The symbolic link is resolved like the paths of the histories and the versions.
+		{"symbolic link", root, filepath.Join(link, "page.md"), "docs/src/page.md"},
+		{"outside repository", root, filepath.Join("..", "src", "page.md"), "../src/page.md"},
+		{"no repository", "", filepath.Join("src", "page.md"), "src/page.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RepositoryPath(tt.root, tt.fileName); got != tt.want {
+				t.Errorf("RepositoryPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceLinks_Layout(t *testing.T) {
+	links := SourceLinks{
+		Edit:   "https://github.com/org/repo/edit/main/{path}",
+		Source: "https://github.com/org/repo/blob/main/{path}?plain=1&x=y",
+	}
+	layout := `<p>__EDIT_LINK__|__SOURCE_LINK__|__HISTORY_LINK__</p>`
+	want := `<p><a href="https://github.com/org/repo/edit/main/src/%E3%82%AC%E3%82%A4%E3%83%89/a%20b.md" class="edit-link">Edit this page</a>|` +
+		`<a href="https://github.com/org/repo/blob/main/src/%E3%82%AC%E3%82%A4%E3%83%89/a%20b.md?plain=1&amp;x=y" class="source-link">Source</a>|</p>`
+	if got := links.Layout(layout, "src/ガイド/a b.md"); got != want {
+		t.Errorf("SourceLinks.Layout() = %v, want %v", got, want)
+	}
+	want = `<p>||</p>`
+	if got := links.Layout(layout, ""); got != want {
+		t.Errorf("SourceLinks.Layout() = %v, want %v", got, want)
+	}
+}
+
+func TestSourceLinks_Layout_Ref(t *testing.T) {
+	layout := `<p>__EDIT_LINK__|__SOURCE_LINK__|__HISTORY_LINK__</p>`
+	tests := []struct {
+		name  string
+		links SourceLinks
+		want  string
+	}{
+		{
+			name: "Working tree",
+			links: SourceLinks{
+				Edit:    "https://github.com/org/repo/edit/{ref}/{path}",
+				Source:  "https://github.com/org/repo/blob/{ref}/{path}",
+				History: "https://github.com/org/repo/commits/main/{path}",
+			},
+			want: `<p><a href="https://github.com/org/repo/edit/HEAD/src/a.md" class="edit-link">Edit this page</a>|` +
+				`<a href="https://github.com/org/repo/blob/HEAD/src/a.md" class="source-link">Source</a>|` +
+				`<a href="https://github.com/org/repo/commits/main/src/a.md" class="history-link">History</a></p>`,
+		},
+		{
+			name: "Git ref",
+			links: SourceLinks{
+				Edit:    "https://github.com/org/repo/edit/{ref}/{path}",
+				Source:  "https://github.com/org/repo/blob/{ref}/{path}",
+				History: "https://github.com/org/repo/commits/main/{path}",
+				Ref:     "release/v1.0 #1",
+			},
+			want: `<p>|<a href="https://github.com/org/repo/blob/release/v1.0%20%231/src/a.md" class="source-link">Source</a>|</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.links.Layout(layout, "src/a.md"); got != tt.want {
+				t.Errorf("SourceLinks.Layout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark/ast"
+)
+
+const (
+	TOC_MARKER         = "[[toc]]"
+	DEFAULT_TOC_MIN    = 1
+	DEFAULT_TOC_MAX    = 4
+	TOC_LEVEL_MIN      = 1
+	TOC_LEVEL_MAX      = 6
+	TOC_LIST_UNORDERED = "ul"
+	TOC_LIST_ORDERED   = "ol"
+)
+
+// TOCOptions configures the table of contents.
+type TOCOptions struct {
+	MinLevel  int    // MinLevel is the smallest heading level listed in the table of contents.
+	MaxLevel  int    // MaxLevel is the largest heading level listed in the table of contents.
+	List      string // List is "ul" or "ol" for nested lists. Otherwise the headings are listed as flat paragraphs.
+	ScrollSpy bool   // ScrollSpy highlights the current section in the header list.
+}
+
+// checkTOCLevel returns an error if a level is not between 1 and 6.
+// name is the name of the setting that is shown in the error.
+func checkTOCLevel(level int, name string) error {
+	if level < TOC_LEVEL_MIN || TOC_LEVEL_MAX < level {
+		return errors.Errorf("%s is %d, but it must be between %d and %d", name, level, TOC_LEVEL_MIN, TOC_LEVEL_MAX)
+	}
+	return nil
+}
+
+// validateTOCLevels returns an error if a level is not between 1 and 6 or minLevel is larger than maxLevel.
+func validateTOCLevels(minLevel, maxLevel int, minName, maxName string) error {
+	if err := checkTOCLevel(minLevel, minName); err != nil {
+		return err
+	}
+	if err := checkTOCLevel(maxLevel, maxName); err != nil {
+		return err
+	}
+	if minLevel > maxLevel {
+		return errors.Errorf("%s (%d) is larger than %s (%d)", minName, minLevel, maxName, maxLevel)
+	}
+	return nil
+}
+
+// Validate returns an error if TOC_MIN_LEVEL or TOC_MAX_LEVEL is not between 1 and 6, or TOC_MIN_LEVEL is larger than TOC_MAX_LEVEL.
+func (o TOCOptions) Validate() error {
+	return validateTOCLevels(o.MinLevel, o.MaxLevel, "TOC_MIN_LEVEL", "TOC_MAX_LEVEL")
+}
+
+// ForPage returns the options overridden by the metadata of a page.
+// It returns an error if the levels of the page are invalid.
+func (o TOCOptions) ForPage(pm PageMeta) (TOCOptions, error) {
+	if pm.TOCMinLevel != 0 {
+		o.MinLevel = pm.TOCMinLevel
+	}
+	if pm.TOCMaxLevel != 0 {
+		o.MaxLevel = pm.TOCMaxLevel
+	}
+	if err := validateTOCLevels(o.MinLevel, o.MaxLevel, "tocMinLevel", "tocMaxLevel"); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+// validatePageTOCLevels returns an error if tocMinLevel or tocMaxLevel in the metadata of a page is invalid.
+// It also returns the key of the invalid level so that the error can be located in the file.
+// The levels that are not specified are taken from TOCOptions, so they are validated by ForPage.
+func validatePageTOCLevels(pm PageMeta) (string, error) {
+	if pm.TOCMinLevel != 0 {
+		if err := checkTOCLevel(pm.TOCMinLevel, "tocMinLevel"); err != nil {
+			return "tocMinLevel", err
+		}
+	}
+	if pm.TOCMaxLevel != 0 {
+		if err := checkTOCLevel(pm.TOCMaxLevel, "tocMaxLevel"); err != nil {
+			return "tocMaxLevel", err
+		}
+		if pm.TOCMinLevel > pm.TOCMaxLevel {
+			return "tocMaxLevel", errors.Errorf("tocMinLevel (%d) is larger than tocMaxLevel (%d)", pm.TOCMinLevel, pm.TOCMaxLevel)
+		}
+	}
+	return "", nil
+}
+
+// filterHeadings returns the headings whose levels are between MinLevel and MaxLevel.
+func (o TOCOptions) filterHeadings(headings []Heading) []Heading {
+	filtered, _ := Filter(headings, func(heading Heading, _ int) (bool, error) {
+		return o.MinLevel <= heading.Level && heading.Level <= o.MaxLevel, nil
+	})
+	return filtered
+}
+
+// tocLink generates the link to a heading.
+func tocLink(heading Heading) string {
+	return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(heading.ID), html.EscapeString(heading.Text))
+}
+
+// createFlatTOC lists the headings as paragraphs that have the class of their levels.
+func createFlatTOC(headings []Heading) string {
+	items, _ := Map(headings, func(heading Heading, _ int) (string, error) {
+		return fmt.Sprintf(`<p class="h%d">%s</p>`, heading.Level, tocLink(heading)), nil
+	})
+	return strings.Join(items, "\n")
+}
+
+// createNestedTOC lists the headings as nested lists.
+// A heading is nested in the list of the preceding heading whose level is smaller.
+func createNestedTOC(headings []Heading, list string) string {
+	var toc strings.Builder
+	levels := []int{}
+	for _, heading := range headings {
+		switch {
+		case len(levels) == 0:
+			toc.WriteString("<" + list + ">")
+			levels = append(levels, heading.Level)
+		case heading.Level > levels[len(levels)-1]:
+			toc.WriteString("\n<" + list + ">")
+			levels = append(levels, heading.Level)
+		default:
+			toc.WriteString("</li>")
+			for len(levels) > 1 && heading.Level <= levels[len(levels)-2] {
+				toc.WriteString("\n</" + list + ">\n</li>")
+				levels = levels[:len(levels)-1]
+			}
+		}
+		toc.WriteString("\n<li>" + tocLink(heading))
+	}
+	for i := len(levels); i > 0; i-- {
+		toc.WriteString("</li>\n</" + list + ">")
+		if i > 1 {
+			toc.WriteString("\n")
+		}
+	}
+	return toc.String()
+}
+
+// CreateTOC generates the HTML of a table of contents from the headings of a document.
+func CreateTOC(headings []Heading, opts TOCOptions, class string) string {
+	headings = opts.filterHeadings(headings)
+	content := ""
+	if opts.List == TOC_LIST_UNORDERED || opts.List == TOC_LIST_ORDERED {
+		content = createNestedTOC(headings, opts.List)
+	} else {
+		content = createFlatTOC(headings)
+	}
+	return fmt.Sprintf(`<nav class="%s">%s</nav>`, class, content)
+}
+
+// CreateHeaderList generates HTML for the header list that is displayed on the right side of a page.
+func CreateHeaderList(headings []Heading, opts TOCOptions) string {
+	class := "header-list"
+	if opts.ScrollSpy {
+		class += " scroll-spy"
+	}
+	return CreateTOC(headings, opts, class)
+}
+
+var KindTOC = ast.NewNodeKind("TOC")
+
+// TOC is a block node that is replaced with the table of contents of the document.
+type TOC struct {
+	ast.BaseBlock
+	HTML string
+}
+
+func (n *TOC) Kind() ast.NodeKind {
+	return KindTOC
+}
+
+func (n *TOC) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// isTOCMarker determines if a paragraph consists only of the "[[toc]]" marker.
+func isTOCMarker(node ast.Node, source []byte) bool {
+	paragraph, ok := node.(*ast.Paragraph)
+	if !ok || paragraph.Lines().Len() != 1 {
+		return false
+	}
+	line := paragraph.Lines().At(0)
+	return strings.TrimSpace(string(line.Value(source))) == TOC_MARKER
+}
+
+// replaceTOCMarkers replaces the paragraphs that consist only of the "[[toc]]" marker with TOC nodes.
+func replaceTOCMarkers(root ast.Node, source []byte) []*TOC {
+	tocs := []*TOC{}
+	for c := root.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		if isTOCMarker(c, source) {
+			toc := &TOC{}
+			root.ReplaceChild(root, c, toc)
+			tocs = append(tocs, toc)
+		}
+		c = next
+	}
+	return tocs
+}
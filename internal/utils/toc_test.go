@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateTOC(t *testing.T) {
+	headings := []Heading{
+		{Level: 1, ID: "Title", Text: "Title"},
+		{Level: 2, ID: "A", Text: "A"},
+		{Level: 3, ID: "A1", Text: "A1"},
+		{Level: 5, ID: "A1a", Text: "A1a"},
+		{Level: 3, ID: "A2", Text: "A2"},
+		{Level: 2, ID: "B", Text: "B & C"},
+	}
+	type args struct {
+		headings []Heading
+		opts     TOCOptions
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "Flat",
+			args: args{headings: headings, opts: TOCOptions{MinLevel: 2, MaxLevel: 3}},
+			want: `<nav class="toc"><p class="h2"><a href="#A">A</a></p>` + "\n" +
+				`<p class="h3"><a href="#A1">A1</a></p>` + "\n" +
+				`<p class="h3"><a href="#A2">A2</a></p>` + "\n" +
+				`<p class="h2"><a href="#B">B &amp; C</a></p></nav>`,
+		},
+		{
+			name: "Nested",
+			args: args{headings: headings, opts: TOCOptions{MinLevel: 2, MaxLevel: 6, List: "ul"}},
+			want: `<nav class="toc"><ul>` + "\n" +
+				`<li><a href="#A">A</a>` + "\n" +
+				`<ul>` + "\n" +
+				`<li><a href="#A1">A1</a>` + "\n" +
+				`<ul>` + "\n" +
+				`<li><a href="#A1a">A1a</a></li>` + "\n" +
+				`</ul>` + "\n" +
+				`</li>` + "\n" +
+				`<li><a href="#A2">A2</a></li>` + "\n" +
+				`</ul>` + "\n" +
+				`</li>` + "\n" +
+				`<li><a href="#B">B &amp; C</a></li>` + "\n" +
+				`</ul></nav>`,
+		},
+		{
+			name: "Nested starting with a deep heading",
+			args: args{headings: []Heading{
+				{Level: 3, ID: "A", Text: "A"},
+				{Level: 2, ID: "B", Text: "B"},
+			}, opts: TOCOptions{MinLevel: 1, MaxLevel: 6, List: "ol"}},
+			want: `<nav class="toc"><ol>` + "\n" +
+				`<li><a href="#A">A</a></li>` + "\n" +
+				`<li><a href="#B">B</a></li>` + "\n" +
+				`</ol></nav>`,
+		},
+		{
+			name: "No headings",
+			args: args{headings: []Heading{}, opts: TOCOptions{MinLevel: 1, MaxLevel: 6, List: "ul"}},
+			want: `<nav class="toc"></nav>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreateTOC(tt.args.headings, tt.args.opts, "toc"); got != tt.want {
+				t.Errorf("CreateTOC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOCOptions_ForPage(t *testing.T) {
+	opts := TOCOptions{MinLevel: 1, MaxLevel: 4, List: "ul"}
+	got, err := opts.ForPage(PageMeta{TOCMaxLevel: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TOCOptions{MinLevel: 1, MaxLevel: 6, List: "ul"}
+	if got != want {
+		t.Errorf("TOCOptions.ForPage() = %v, want %v", got, want)
+	}
+	if _, err := opts.ForPage(PageMeta{TOCMinLevel: 5}); err == nil {
+		t.Errorf("TOCOptions.ForPage() error = nil, want an error for tocMinLevel larger than MaxLevel")
+	}
+}
+
+func TestTOCOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    TOCOptions
+		wantErr bool
+	}{
+		{name: "Valid", opts: TOCOptions{MinLevel: 1, MaxLevel: 6}},
+		{name: "Same levels", opts: TOCOptions{MinLevel: 3, MaxLevel: 3}},
+		{name: "Min level is 0", opts: TOCOptions{MinLevel: 0, MaxLevel: 4}, wantErr: true},
+		{name: "Max level is 7", opts: TOCOptions{MinLevel: 1, MaxLevel: 7}, wantErr: true},
+		{name: "Min level is larger than max level", opts: TOCOptions{MinLevel: 4, MaxLevel: 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("TOCOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDocument_TOCLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "Valid", content: "{\"tocMinLevel\": 2, \"tocMaxLevel\": 5}\n---\n# T"},
+		{name: "Out of range", content: "{\n\"category\": \"a\",\n\"tocMaxLevel\": 7\n}\n---\n# T", wantErr: "a.md:3: tocMaxLevel is 7"},
+		{name: "Min level is larger than max level", content: "{\n\"tocMinLevel\": 4,\n\"tocMaxLevel\": 2\n}\n---\n# T", wantErr: "a.md:3: tocMinLevel (4) is larger than tocMaxLevel (2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDocument("a.md", []byte(tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParseDocument() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("ParseDocument() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplaceTOCMarkers(t *testing.T) {
+	doc := ParseMarkdown("# Title\n\n[[toc]]\n\n## Section\n\n`[[toc]]`")
+	doc.SetTOC(CreateTOC(doc.Headings, TOCOptions{MinLevel: 2, MaxLevel: 2}, "toc"))
+	got, err := doc.HTML()
+	if err != nil {
+		t.Errorf("Document.HTML() error = %v", err)
+		return
+	}
+	want := `<h1 id="Title"><a href="#Title">Title</a></h1>` + "\n" +
+		`<nav class="toc"><p class="h2"><a href="#Section">Section</a></p></nav>` + "\n" +
+		`<h2 id="Section"><a href="#Section">Section</a></h2>` + "\n" +
+		`<p><code>[[toc]]</code></p>` + "\n"
+	if got != want {
+		t.Errorf("Document.HTML() = %v, want %v", got, want)
+	}
+}
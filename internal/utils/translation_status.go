@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TRANSLATION_OK      = "ok"
+	TRANSLATION_MISSING = "missing"
+	TRANSLATION_STALE   = "stale"
+
+	// SOURCE_HASH_LENGTH is the number of hexadecimal digits of a source hash.
+	SOURCE_HASH_LENGTH = 12
+)
+
+// TranslationStatus is the state of a page in a language.
+type TranslationStatus struct {
+	Key      string `json:"key"`
+	Language string `json:"language"`
+	Status   string `json:"status"`
+	// File is the markdown file of the page, or "" if the page is missing.
+	File string `json:"file,omitempty"`
+	// Source is the markdown file of the page in the default language.
+	Source string `json:"source,omitempty"`
+	// SourceHash is the current hash of the source. It is written as sourceHash in the metadata of the translation when the translation is updated.
+	SourceHash string `json:"sourceHash,omitempty"`
+}
+
+// SourceHash returns the hash of the markdown text of the document.
+// The metadata is not hashed, so that updating sourceHash of a translation does not change the hash of the translation.
+func (d *Document) SourceHash() string {
+	sum := sha256.Sum256(d.Source)
+	return hex.EncodeToString(sum[:])[:SOURCE_HASH_LENGTH]
+}
+
+// isStale determines if a translation is older than its source.
+// If the translation has sourceHash, it is compared with the hash of the source.
+// Otherwise the date of the translation is compared with the date of the source.
+func isStale(translation, source *Document) bool {
+	if translation.PageMeta.SourceHash != "" {
+		return translation.PageMeta.SourceHash != source.SourceHash()
+	}
+	translated, err := time.Parse(DateTime, translation.PageMeta.Date)
+	if err != nil {
+		return false
+	}
+	updated, err := time.Parse(DateTime, source.PageMeta.Date)
+	if err != nil {
+		return false
+	}
+	return updated.After(translated)
+}
+
+// TranslationStatuses pairs the documents with their translations, and returns the state of each page in each language.
+// The page in the default language is the source of the translations.
+// The statuses are sorted by the translation keys and the order of the languages.
+func TranslationStatuses(docs []*Document, sourceDir string) ([]TranslationStatus, error) {
+	if len(markdownOptions.Languages) < 2 {
+		return nil, errors.New("LANGUAGES must have two or more languages")
+	}
+	pages := map[string]map[string]*Document{}
+	for _, doc := range docs {
+		key := doc.TranslationKey(sourceDir)
+		if pages[key] == nil {
+			pages[key] = map[string]*Document{}
+		}
+		lang := doc.Language(sourceDir)
+		if existing, exists := pages[key][lang]; exists {
+			return nil, errors.Errorf("%s and %s have the same translation key %q in %s", existing.FileName, doc.FileName, key, lang)
+		}
+		pages[key][lang] = doc
+	}
+	keys := make([]string, 0, len(pages))
+	for key := range pages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	statuses := []TranslationStatus{}
+	for _, key := range keys {
+		source := pages[key][defaultLanguage()]
+		for _, l := range markdownOptions.Languages {
+			status := TranslationStatus{Key: key, Language: l.Code, Status: TRANSLATION_OK}
+			doc, exists := pages[key][l.Code]
+			if exists {
+				status.File = doc.FileName
+			} else {
+				status.Status = TRANSLATION_MISSING
+			}
+			if source != nil && l.Code != defaultLanguage() {
+				status.Source = source.FileName
+				status.SourceHash = source.SourceHash()
+				if exists && isStale(doc, source) {
+					status.Status = TRANSLATION_STALE
+				}
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslationStatuses(t *testing.T) {
+	ConfigureMarkdown(MarkdownOptions{Languages: ParseLanguages("ja,en")})
+	defer ConfigureMarkdown(MarkdownOptions{})
+	parse := func(fileName, content string) *Document {
+		doc, err := ParseDocument(fileName, []byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	source := parse("src/a.md", "{\"date\":\"2024-02-01 00:00\"}\n---\n# A")
+	docs := []*Document{
+		source,
+		parse("src/a.en.md", "{\"date\":\"2024-01-01 00:00\"}\n---\n# A"),
+		parse("src/b.md", "{}\n---\n# B"),
+		parse("src/en/c.md", "{\"sourceHash\":\""+parse("src/b.md", "{}\n---\n# B").SourceHash()+"\",\"translationKey\":\"b\"}\n---\n# B"),
+		parse("src/en/d.md", "{}\n---\n# D"),
+	}
+	got, err := TranslationStatuses(docs, "src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TranslationStatus{
+		{Key: "a", Language: "ja", Status: TRANSLATION_OK, File: "src/a.md"},
+		{Key: "a", Language: "en", Status: TRANSLATION_STALE, File: "src/a.en.md", Source: "src/a.md", SourceHash: source.SourceHash()},
+		{Key: "b", Language: "ja", Status: TRANSLATION_OK, File: "src/b.md"},
+		{Key: "b", Language: "en", Status: TRANSLATION_OK, File: "src/en/c.md", Source: "src/b.md", SourceHash: docs[2].SourceHash()},
+		{Key: "d", Language: "ja", Status: TRANSLATION_MISSING},
+		{Key: "d", Language: "en", Status: TRANSLATION_OK, File: "src/en/d.md"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TranslationStatuses() = %v, want %v", got, want)
+	}
+
+	docs[3].PageMeta.SourceHash = "0"
+	got, err = TranslationStatuses(docs, "src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[3].Status != TRANSLATION_STALE {
+		t.Errorf("TranslationStatuses()[3].Status = %v, want %v", got[3].Status, TRANSLATION_STALE)
+	}
+}
+
+func TestTranslationStatuses_SingleLanguage(t *testing.T) {
+	if _, err := TranslationStatuses(nil, "src"); err == nil {
+		t.Error("TranslationStatuses() error = nil, want an error without languages")
+	}
+}
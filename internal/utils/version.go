@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+)
+
+const (
+	// GIT_VERSION_PREFIX is the prefix of the source of a version that is read from a git ref such as "git:v1.0.0".
+	GIT_VERSION_PREFIX     = "git:"
+	DEFAULT_VERSION_BANNER = "You are viewing the documentation of an old version. The latest version is"
+)
+
+// Version is a version of the documents that is generated into the directory of its name.
+type Version struct {
+	Name string
+	// Source is the directory of the markdown files, or a git ref prefixed with "git:".
+	Source string
+}
+
+// ParseVersions parses a comma separated list of versions such as "v1=git:v1.0.0,v2=docs/v2,latest=src".
+func ParseVersions(value string) ([]Version, error) {
+	versions := []Version{}
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		name, source, found := strings.Cut(item, "=")
+		name, source = strings.TrimSpace(name), strings.TrimSpace(source)
+		if !found || name == "" || source == "" || strings.ContainsAny(name, `/\`) {
+			return nil, errors.Errorf("invalid version: %s", item)
+		}
+		versions = append(versions, Version{Name: name, Source: source})
+	}
+	return versions, nil
+}
+
+// Checkout returns the directory of the markdown files of the version and the function that removes the temporary files.
+// If the source is a git ref, the whole repository at the ref is extracted into a temporary directory,
+// so that the code and the partials outside sourceDir are also read from the ref.
+// The returned directory is the directory of sourceDir in the extracted repository.
+func (v Version) Checkout(sourceDir string) (string, func(), error) {
+	ref, isGit := strings.CutPrefix(v.Source, GIT_VERSION_PREFIX)
+	if !isGit {
+		return v.Source, func() {}, nil
+	}
+	root, rel, err := repositoryDir(sourceDir)
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp("", "mujidoc-"+v.Name+"-")
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	remove := func() { os.RemoveAll(dir) }
+	if err := exportGitTree(ref, root, dir); err != nil {
+		remove()
+		return "", nil, err
+	}
+	versionDir := filepath.Join(dir, rel)
+	if info, err := os.Stat(versionDir); err != nil || !info.IsDir() {
+		remove()
+		return "", nil, errors.Errorf("%s does not exist at %s", sourceDir, ref)
+	}
+	return versionDir, remove, nil
+}
+
+// GitRoot returns the root of the git repository of the current directory. Symbolic links in the path are resolved.
+func GitRoot() (string, error) {
+	out, err := runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(strings.TrimSpace(out))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return root, nil
+}
+
+// pathInRepository returns the slash-separated path of a file relative to root, or false if the file is outside root.
+func pathInRepository(root, fileName string) (string, bool) {
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		return "", false
+	}
+	// シンボリックリンクを解決してからリポジトリのルートと比較する
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// repositoryDir returns the root of the git repository of the current directory and the path of dir relative to it.
+func repositoryDir(dir string) (string, string, error) {
+	root, err := GitRoot()
+	if err != nil {
+		return "", "", err
+	}
+	rel, ok := pathInRepository(root, dir)
+	if !ok {
+		return "", "", errors.Errorf("%s is not in the git repository %s", dir, root)
+	}
+	return root, filepath.FromSlash(rel), nil
+}
+
+// exportGitTree writes the files of the git repository in root at a ref into destDir.
+// The files are read with "git archive".
+func exportGitTree(ref, root, destDir string) error {
+	var stdout, stderr bytes.Buffer
+	// サブディレクトリで実行するとそのディレクトリしか出力されないのでルートで実行する
+	treeish := ref + ":"
+	cmd := exec.Command("git", "archive", "--format=tar", treeish)
+	cmd.Dir = root
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return errors.Errorf("git archive %s: %s: %s", treeish, err, message)
+		}
+		return errors.Errorf("git archive %s: %s", treeish, err)
+	}
+	return extractTar(&stdout, destDir)
+}
+
+// extractTar writes the directories and the regular files in a tar archive into destDir.
+// The other entries such as symbolic links are skipped.
+func extractTar(r io.Reader, destDir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return errors.Errorf("invalid path in the archive: %s", header.Name)
+		}
+		path := filepath.Join(destDir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return errors.WithStack(err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return errors.WithStack(err)
+			}
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+}
+
+// Versions holds the pages of the versions, and renders the version switcher and the banner of the old versions.
+// The paths of the pages are relative to the directory of the version such as "/guide/page.html".
+type Versions struct {
+	versions []Version
+	latest   string
+	baseURL  string
+	// paths holds the paths of the pages in each version.
+	paths map[string]map[string]bool
+}
+
+// NewVersions creates Versions. If latest is "", the last version is the latest version.
+// It returns an error if latest is not one of the versions.
+func NewVersions(versions []Version, latest, baseURL string) (*Versions, error) {
+	if latest == "" && len(versions) > 0 {
+		latest = versions[len(versions)-1].Name
+	}
+	if latest != "" && !slices.ContainsFunc(versions, func(v Version) bool { return v.Name == latest }) {
+		return nil, errors.Errorf("latest version %s is not one of the versions", latest)
+	}
+	return &Versions{versions: versions, latest: latest, baseURL: baseURL, paths: map[string]map[string]bool{}}, nil
+}
+
+// Register adds the paths of the pages of a version.
+func (v *Versions) Register(version string, paths ...string) {
+	if v.paths[version] == nil {
+		v.paths[version] = map[string]bool{}
+	}
+	for _, path := range paths {
+		v.paths[version][path] = true
+	}
+}
+
+// url returns the URL of the page at path in a version, or the URL of the top page of the version if it does not have the page.
+func (v *Versions) url(version, path string) string {
+	if !v.paths[version][path] {
+		path = "/"
+	}
+	return v.baseURL + "/" + version + path
+}
+
+// Switcher returns the navigation that links to the page at path in each version.
+// It returns "" if versions are not configured.
+func (v *Versions) Switcher(version, path string) string {
+	if len(v.versions) == 0 {
+		return ""
+	}
+	var nav strings.Builder
+	nav.WriteString(`<nav class="version-switcher">`)
+	for _, ver := range v.versions {
+		current := ""
+		if ver.Name == version {
+			current = ` class="current" aria-current="page"`
+		}
+		nav.WriteString(fmt.Sprintf(`<a href="%s"%s>%s</a>`, html.EscapeString(v.url(ver.Name, path)), current, html.EscapeString(ver.Name)))
+	}
+	nav.WriteString("</nav>")
+	return nav.String()
+}
+
+// Banner returns the banner that links to the page at path in the latest version.
+// It returns "" if the version is the latest version or versions are not configured.
+func (v *Versions) Banner(version, path, message string) string {
+	if len(v.versions) == 0 || version == v.latest {
+		return ""
+	}
+	return fmt.Sprintf(`<div class="version-banner" role="note">%s <a href="%s">%s</a></div>`,
+		html.EscapeString(message), html.EscapeString(v.url(v.latest, path)), html.EscapeString(v.latest))
+}
+
+// VersionLayout replaces __VERSION__, __VERSION_SWITCHER__ and __VERSION_BANNER__ in a layout for the page at path in a version.
+func (v *Versions) VersionLayout(layout, version, path, message string) string {
+	layout = strings.ReplaceAll(layout, VERSION, html.EscapeString(version))
+	layout = strings.ReplaceAll(layout, VERSION_SWITCHER, v.Switcher(version, path))
+	return strings.ReplaceAll(layout, VERSION_BANNER, v.Banner(version, path, message))
+}
+
+// WriteVersionRedirect writes index.html into outputDir that redirects to the latest version.
+func (v *Versions) WriteVersionRedirect(outputDir string) error {
+	url := html.EscapeString(v.baseURL + "/" + v.latest + "/")
+	page := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="UTF-8" />
+    <meta http-equiv="refresh" content="0; url=%s" />
+    <link rel="canonical" href="%s" />
+  </head>
+  <body>
+    <a href="%s">%s</a>
+  </body>
+</html>
+`, url, url, url, url)
+	return errors.WithStack(os.WriteFile(filepath.Join(outputDir, "index.html"), []byte(page), 0644))
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseVersions(t *testing.T) {
+	got, err := ParseVersions(" v1=git:v1.0.0, v2 = docs/v2 ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Version{{Name: "v1", Source: "git:v1.0.0"}, {Name: "v2", Source: "docs/v2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseVersions() = %v, want %v", got, want)
+	}
+	for _, value := range []string{"v1", "v1=", "=src", "a/b=src"} {
+		if _, err := ParseVersions(value); err == nil {
+			t.Errorf("ParseVersions(%q) error = nil, want an error", value)
+		}
+	}
+}
+
+func createTar(t *testing.T, headers ...*tar.Header) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range headers {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(make([]byte, header.Size)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTar(t *testing.T) {
+	dir := t.TempDir()
+	archive := createTar(t,
+		&tar.Header{Name: "guide/", Typeflag: tar.TypeDir, Mode: 0755},
+		&tar.Header{Name: "guide/page.md", Typeflag: tar.TypeReg, Mode: 0644, Size: 3},
+		&tar.Header{Name: "link.md", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+	)
+	if err := extractTar(archive, dir); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "guide", "page.md")); err != nil || len(content) != 3 {
+		t.Errorf("extractTar() wrote %v, %v", content, err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "link.md")); !os.IsNotExist(err) {
+		t.Errorf("extractTar() wrote a symbolic link")
+	}
+
+	archive = createTar(t, &tar.Header{Name: "../evil.md", Typeflag: tar.TypeReg, Mode: 0644, Size: 1})
+	if err := extractTar(archive, dir); err == nil {
+		t.Error("extractTar() error = nil, want an error for a path outside the directory")
+	}
+}
+
+// createGitRepository creates a git repository in a temporary directory and changes the current directory to it.
+// The current directory is restored when the test finishes.
+func createGitRepository(t *testing.T) string {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	git(t, "init", "--quiet")
+	return dir
+}
+
+// git runs a git command in the current directory with a fixed author.
+func git(t *testing.T, args ...string) {
+	args = append([]string{"-c", "user.name=Alice", "-c", "user.email=alice@example.com", "-c", "commit.gpgsign=false"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+// writeFiles writes files whose paths are relative to the current directory.
+func writeFiles(t *testing.T, files map[string]string) {
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestVersion_Checkout(t *testing.T) {
+	createGitRepository(t)
+	writeFiles(t, map[string]string{
+		"docs/src/page.md":            "{}\n---\n# Page\n\n{{< include \"_partials/setup.md\" >}}\n",
+		"docs/src/_partials/setup.md": "v1 setup\n",
+		"docs/examples/main.go":       "package v1\n",
+	})
+	git(t, "add", "-A")
+	git(t, "commit", "--quiet", "-m", "v1")
+	git(t, "tag", "v1")
+	writeFiles(t, map[string]string{
+		"docs/src/_partials/setup.md": "v2 setup\n",
+		"docs/examples/main.go":       "package v2\n",
+	})
+	if err := os.Chdir("docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, remove, err := Version{Name: "v1", Source: "git:v1"}.Checkout("src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The partials and the code outside the source directory are read from the ref.
+	for name, want := range map[string]string{
+		filepath.Join(dir, "_partials", "setup.md"):     "v1 setup\n",
+		filepath.Join(dir, "..", "examples", "main.go"): "package v1\n",
+	} {
+		if content, err := os.ReadFile(name); err != nil || string(content) != want {
+			t.Errorf("Version.Checkout() wrote %s = %q, %v, want %q", name, content, err, want)
+		}
+	}
+	remove()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("remove() did not remove %s", dir)
+	}
+
+	if _, _, err := (Version{Name: "v1", Source: "git:v1"}).Checkout("missing"); err == nil {
+		t.Error("Version.Checkout() error = nil, want an error for a directory that does not exist at the ref")
+	}
+	if dir, _, err := (Version{Name: "v2", Source: "src"}).Checkout("src"); err != nil || dir != "src" {
+		t.Errorf("Version.Checkout() = %v, %v, want src", dir, err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	versions, err := NewVersions([]Version{{Name: "v1"}, {Name: "v2"}}, "", "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions.Register("v1", "/", "/a.html")
+	versions.Register("v2", "/", "/a.html", "/b.html")
+
+	want := `<nav class="version-switcher"><a href="https://example.com/v1/" class="current" aria-current="page">v1</a>` +
+		`<a href="https://example.com/v2/b.html">v2</a></nav>`
+	if got := versions.Switcher("v1", "/b.html"); got != want {
+		t.Errorf("Versions.Switcher() = %v, want %v", got, want)
+	}
+
+	want = `<div class="version-banner" role="note">Old &amp; unsupported. <a href="https://example.com/v2/a.html">v2</a></div>`
+	if got := versions.Banner("v1", "/a.html", "Old & unsupported."); got != want {
+		t.Errorf("Versions.Banner() = %v, want %v", got, want)
+	}
+	if got := versions.Banner("v2", "/a.html", "Old"); got != "" {
+		t.Errorf("Versions.Banner() = %v, want no banner in the latest version", got)
+	}
+
+	want = `<span>v2</span><nav class="version-switcher"><a href="https://example.com/v1/a.html">v1</a>` +
+		`<a href="https://example.com/v2/a.html" class="current" aria-current="page">v2</a></nav>`
+	if got := versions.VersionLayout(`<span>__VERSION__</span>__VERSION_SWITCHER____VERSION_BANNER__`, "v2", "/a.html", "Old"); got != want {
+		t.Errorf("Versions.VersionLayout() = %v, want %v", got, want)
+	}
+}
+
+func TestVersions_NotConfigured(t *testing.T) {
+	versions, err := NewVersions([]Version{}, "", "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions.VersionLayout(`<p>__VERSION____VERSION_SWITCHER____VERSION_BANNER__</p>`, "", "/a.html", "Old"); got != "<p></p>" {
+		t.Errorf("Versions.VersionLayout() = %v, want <p></p>", got)
+	}
+}
+
+func TestNewVersions_UnknownLatest(t *testing.T) {
+	if _, err := NewVersions([]Version{{Name: "v1"}, {Name: "v2"}}, "v3", "https://example.com"); err == nil {
+		t.Errorf("NewVersions() error = nil, want an error")
+	}
+	if _, err := NewVersions([]Version{{Name: "v1"}, {Name: "v2"}}, "v1", "https://example.com"); err != nil {
+		t.Errorf("NewVersions() error = %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	WRITING_MODE_VERTICAL   = "vertical"
+	WRITING_MODE_HORIZONTAL = "horizontal"
+)
+
+// Vertical determines if the page is written vertically.
+// The writing mode in the metadata of the page overrides MarkdownOptions.WritingMode.
+func (d *Document) Vertical() bool {
+	mode := markdownOptions.WritingMode
+	if d.PageMeta.WritingMode != "" {
+		mode = d.PageMeta.WritingMode
+	}
+	return mode == WRITING_MODE_VERTICAL
+}
+
+// tcyRe matches numbers, and the numbers of one or two digits are set horizontally in vertical text.
+var tcyRe = regexp.MustCompile(`[0-9]+`)
+
+// isTCY determines if the number at text[start:end] is set horizontally.
+// The numbers in Latin words and decimals such as "HTML5", "x86" and "1.5" are set sideways with the words.
+func isTCY(text string, start, end int) bool {
+	if end-start > 2 {
+		return false
+	}
+	isLatin := func(c byte) bool {
+		return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '.'
+	}
+	return (start == 0 || !isLatin(text[start-1])) && (end == len(text) || !isLatin(text[end]))
+}
+
+// tcySkippedElements holds the elements whose text is not set vertically or must not be changed.
+var tcySkippedElements = map[string]bool{
+	"pre": true, "code": true, "math": true, "script": true, "style": true, "svg": true,
+}
+
+// tateChuYoko wraps the numbers of one or two digits that are not in Latin words in the text of the HTML with <span class="tcy">,
+// so that they are set horizontally in vertical text. The text in code, formulas, diagrams and the placeholders of trusted HTML is not changed.
+func tateChuYoko(htmlStr string) string {
+	var buf strings.Builder
+	z := html.NewTokenizer(strings.NewReader(htmlStr))
+	skipped := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return htmlStr
+			}
+			return buf.String()
+		}
+		raw := z.Raw()
+		switch tt {
+		case html.StartTagToken, html.EndTagToken:
+			name, _ := z.TagName()
+			if tcySkippedElements[string(name)] {
+				if tt == html.StartTagToken {
+					skipped++
+				} else if skipped > 0 {
+					skipped--
+				}
+			}
+		case html.TextToken:
+			if skipped == 0 && bytes.ContainsAny(raw, "0123456789") {
+				text := string(z.Text())
+				placeholders := trustedPlaceholderRe.FindAllStringIndex(text, -1)
+				last := 0
+				for _, loc := range tcyRe.FindAllStringIndex(text, -1) {
+					if !isTCY(text, loc[0], loc[1]) || inRanges(placeholders, loc[0]) {
+						continue
+					}
+					buf.WriteString(html.EscapeString(text[last:loc[0]]))
+					buf.WriteString(`<span class="tcy">` + text[loc[0]:loc[1]] + "</span>")
+					last = loc[1]
+				}
+				buf.WriteString(html.EscapeString(text[last:]))
+				continue
+			}
+		}
+		buf.Write(raw)
+	}
+}
+
+// inRanges determines if i is in one of the ranges.
+func inRanges(ranges [][]int, i int) bool {
+	for _, r := range ranges {
+		if r[0] <= i && i < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// verticalBody wraps the body of a vertical page in the container that is scrolled horizontally.
+func verticalBody(body string) string {
+	return `<div class="vertical">` + tateChuYoko(body) + "</div>"
+}
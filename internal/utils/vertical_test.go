@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTateChuYoko(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "Numbers",
+			html: "<p>第3章は12月の2024年</p>",
+			want: `<p>第<span class="tcy">3</span>章は<span class="tcy">12</span>月の2024年</p>`,
+		},
+		{
+			name: "Latin words",
+			html: "<p>HTML5とx86とv1.2とIPv6と1.5倍と(3)</p>",
+			want: `<p>HTML5とx86とv1.2とIPv6と1.5倍と(<span class="tcy">3</span>)</p>`,
+		},
+		{
+			name: "Entities",
+			html: `<p title="1">&lt;1&gt; &amp;&#160;</p>`,
+			want: `<p title="1">&lt;<span class="tcy">1</span>&gt; &amp;` + " </p>",
+		},
+		{
+			name: "Code and formulas",
+			html: "<pre><code>a = 1\n</code></pre><p><code>2</code><math><mn>3</mn></math>4</p>",
+			want: "<pre><code>a = 1\n</code></pre><p><code>2</code><math><mn>3</mn></math><span class=\"tcy\">4</span></p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tateChuYoko(tt.html); got != tt.want {
+				t.Errorf("tateChuYoko() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTateChuYoko_Placeholders(t *testing.T) {
+	placeholder := trustedPlaceholder("SHORTCODE", "<div>1</div>")
+	got := tateChuYoko("<p>" + placeholder + "と1</p>")
+	if want := "<p>" + placeholder + `と<span class="tcy">1</span></p>`; got != want {
+		t.Errorf("tateChuYoko() = %v, want %v", got, want)
+	}
+}
+
+func TestDocument_Vertical(t *testing.T) {
+	tests := []struct {
+		name        string
+		writingMode string
+		md          string
+		want        bool
+	}{
+		{name: "Default", md: "{}\n---\n# A", want: false},
+		{name: "Page", md: "{\"writing-mode\":\"vertical\"}\n---\n# A", want: true},
+		{name: "Site", writingMode: WRITING_MODE_VERTICAL, md: "{}\n---\n# A", want: true},
+		{name: "Horizontal page", writingMode: WRITING_MODE_VERTICAL, md: "{\"writing-mode\":\"horizontal\"}\n---\n# A", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureMarkdown(MarkdownOptions{WritingMode: tt.writingMode})
+			defer ConfigureMarkdown(MarkdownOptions{})
+			doc, err := ParseDocument("a.md", []byte(tt.md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Vertical(); got != tt.want {
+				t.Errorf("Document.Vertical() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePage_Vertical(t *testing.T) {
+	doc, err := ParseDocument("a.md", []byte("{\"writing-mode\":\"vertical\"}\n---\n# 第1章\n\n## 10月\n\n本文"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CreatePage(BODY+HEADER, doc, "", "", "", "", TOCOptions{MinLevel: 2, MaxLevel: 2})
+	if err != nil {
+		t.Errorf("CreatePage() error = %v", err)
+		return
+	}
+	for _, want := range []string{
+		`<div class="vertical"><h1 id="第1章">`,
+		`<span class="tcy">1</span>章</a></h1>`,
+		`<span class="tcy">10</span>月</a></h2>`,
+		"<p>本文</p>\n</div>",
+		`<nav class="header-list"><p class="h2"><a href="#10%E6%9C%88" rel="nofollow">10月</a></p></nav>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CreatePage() = %v, want %v", got, want)
+		}
+	}
+}